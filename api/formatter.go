@@ -1,9 +1,16 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"encoding/xml"
+	"fmt"
 	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/vmihailenco/msgpack"
 )
 
 // FormatterFn is used to format responses.
@@ -14,6 +21,90 @@ type FormatterFn func(r *Response, w http.ResponseWriter) error
 // Otherwise use SendFormat.
 var Formatter FormatterFn = JSON
 
+// Formatters maps media types to the formatter used by NegotiateFormatter.
+// Register additional formatters here (i.e. text/csv).
+var Formatters = map[string]FormatterFn{
+	"application/json":         JSON,
+	"application/xml":          XML,
+	"text/xml":                 XML,
+	"application/msgpack":      MessagePack,
+	"application/problem+json": ProblemJSON,
+}
+
+type formatterKey struct{}
+
+// WithFormatter returns a shallow copy of r with f set as the request's
+// formatter.
+func WithFormatter(r *http.Request, f FormatterFn) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), formatterKey{}, f))
+}
+
+// RequestFormatter returns the formatter set with WithFormatter, or
+// Formatter if none was set.
+//
+//	resp.SendFormat(w, api.RequestFormatter(r))
+func RequestFormatter(r *http.Request) FormatterFn {
+	if f, ok := r.Context().Value(formatterKey{}).(FormatterFn); ok {
+		return f
+	}
+	return Formatter
+}
+
+// formatterWriter is implemented by response writers that carry the
+// request's formatter, such as the one used by middleware.EnforceFormat.
+type formatterWriter interface {
+	Formatter() FormatterFn
+}
+
+// writerFormatter returns the formatter carried by w, or by a writer it
+// wraps, or Formatter if there is none. Wrapped writers are found with
+// an Unwrap() http.ResponseWriter method.
+func writerFormatter(w http.ResponseWriter) FormatterFn {
+	for {
+		if fw, ok := w.(formatterWriter); ok {
+			return fw.Formatter()
+		}
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return Formatter
+		}
+		w = u.Unwrap()
+	}
+}
+
+// NegotiateFormatter returns the formatter in Formatters that best matches
+// the request's Accept header, using quality values and falling back to
+// the order of the header. JSON is returned when the header is missing,
+// is */*, or matches no registered media type.
+//
+//	resp.SendFormat(w, api.NegotiateFormatter(r))
+func NegotiateFormatter(r *http.Request) FormatterFn {
+	best, bestQ := FormatterFn(JSON), 0.0
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		params := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+		f, ok := Formatters[mediaType]
+		if mediaType == "*/*" {
+			f, ok = JSON, true
+		} else if !ok {
+			continue
+		}
+
+		q := 1.0
+		for _, p := range params[1:] {
+			if p = strings.TrimSpace(p); strings.HasPrefix(p, "q=") {
+				if v, err := strconv.ParseFloat(p[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q > bestQ {
+			best, bestQ = f, q
+		}
+	}
+	return best
+}
+
 // JSON formats an API response and writes it as JSON.
 func JSON(r *Response, w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
@@ -26,6 +117,56 @@ func JSON(r *Response, w http.ResponseWriter) error {
 	return json.NewEncoder(w).Encode(r)
 }
 
+// MessagePack formats an API response and writes it as MessagePack.
+// Fields are named using their json struct tags.
+func MessagePack(r *Response, w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/msgpack")
+	w.WriteHeader(r.Status)
+
+	// hide status code for successful responses
+	if r.Success {
+		r.Status = 0
+	}
+	return msgpack.NewEncoder(w).UseJSONTag(true).Encode(r)
+}
+
+// rxJSONPCallback matches safe JavaScript identifiers, optionally
+// namespaced (i.e. jQuery.callback).
+var rxJSONPCallback = regexp.MustCompile(`^[a-zA-Z_$][a-zA-Z0-9_$]*(?:\.[a-zA-Z_$][a-zA-Z0-9_$]*)*$`)
+
+// JSONP returns a formatter that wraps the JSON response in the function
+// named by the request's callback query param. If the callback is missing,
+// longer than 128 characters, or not a safe JavaScript identifier, the
+// response is written as plain JSON.
+//
+//	resp.SendFormat(w, api.JSONP(r))
+func JSONP(req *http.Request) FormatterFn {
+	callback := req.URL.Query().Get("callback")
+	if callback == "" || len(callback) > 128 || !rxJSONPCallback.MatchString(callback) {
+		return JSON
+	}
+
+	return func(r *Response, w http.ResponseWriter) error {
+		w.Header().Set("Content-Type", "application/javascript")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(r.Status)
+
+		// hide status code for successful responses
+		if r.Success {
+			r.Status = 0
+		}
+		b, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+
+		// The leading comment prevents the response being interpreted
+		// as anything other than JavaScript.
+		_, err = fmt.Fprintf(w, "/**/%s(%s);", callback, b)
+		return err
+	}
+}
+
 // XML formats an API response and writes it as XML.
 func XML(r *Response, w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/xml")
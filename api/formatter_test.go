@@ -2,9 +2,13 @@ package api
 
 import (
 	"bytes"
+	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"reflect"
 	"testing"
+
+	"github.com/vmihailenco/msgpack"
 )
 
 func TestFormatters(t *testing.T) {
@@ -66,6 +70,18 @@ func TestFormatters(t *testing.T) {
 			paging:      Paging{Count: 1, Offset: 0, Limit: 20},
 			want:        []byte(`<response><success>true</success><result><first_name>Jon</first_name><last_name>Doe</last_name><age>30</age></result><paging><total_count>1</total_count><limit>20</limit><offset>0</offset></paging></response>`),
 		},
+		{
+			formatter:   JSON,
+			contentType: "application/json",
+			paging:      Paging{Limit: 20}.WithCursors("b2Zmc2V0PTQw", "b2Zmc2V0PTA="),
+			want:        []byte(`{"success":true,"result":{"first_name":"Jon","last_name":"Doe","age":30},"paging":{"total_count":0,"limit":20,"offset":0,"next":"b2Zmc2V0PTQw","prev":"b2Zmc2V0PTA="}}`),
+		},
+		{
+			formatter:   XML,
+			contentType: "application/xml",
+			paging:      Paging{}.WithCursors("b2Zmc2V0PTQw", ""),
+			want:        []byte(`<response><success>true</success><result><first_name>Jon</first_name><last_name>Doe</last_name><age>30</age></result><paging><total_count>0</total_count><limit>0</limit><offset>0</offset><next>b2Zmc2V0PTQw</next></paging></response>`),
+		},
 		{
 			formatter:   JSON,
 			contentType: "application/json",
@@ -83,7 +99,7 @@ func TestFormatters(t *testing.T) {
 
 		if len(tt.errors) == 0 {
 			response := Success(result)
-			if tt.paging.Count > 0 || tt.paging.Limit > 0 || tt.paging.Offset > 0 {
+			if tt.paging != (Paging{}) {
 				response = response.Paging(tt.paging)
 			}
 
@@ -111,7 +127,7 @@ func TestFormatters(t *testing.T) {
 
 		if len(tt.errors) == 0 {
 			response := Success(result)
-			if tt.paging.Count > 0 || tt.paging.Limit > 0 || tt.paging.Offset > 0 {
+			if tt.paging != (Paging{}) {
 				response = response.Paging(tt.paging)
 			}
 
@@ -133,3 +149,107 @@ func TestFormatters(t *testing.T) {
 		}
 	}
 }
+
+func TestFormatters_MessagePack(t *testing.T) {
+	tests := []struct {
+		response *Response
+		status   int
+		want     Response
+	}{
+		{
+			response: Success(map[string]interface{}{"first_name": "Jon"}).Paging(Paging{Count: 1, Limit: 20}),
+			status:   200,
+			want: Response{
+				Success:    true,
+				Result:     map[string]interface{}{"first_name": "Jon"},
+				Pagination: &Paging{Count: 1, Limit: 20},
+			},
+		},
+		{
+			response: Failure(409, Error{Field: "email", Type: "already_exists", Message: "A user with that email address already exists"}).Response,
+			status:   409,
+			want: Response{
+				Status: 409,
+				Code:   "conflict",
+				Errors: []Error{{Field: "email", Type: "already_exists", Message: "A user with that email address already exists"}},
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		w := httptest.NewRecorder()
+		tt.response.SendFormat(w, MessagePack)
+
+		if w.Code != tt.status {
+			t.Fatalf("TestFormatters_MessagePack (%d): Expected status %d, given %d", i, tt.status, w.Code)
+		} else if ct := w.Header().Get("Content-Type"); ct != "application/msgpack" {
+			t.Fatalf("TestFormatters_MessagePack (%d): unexpected Content-Type: %s", i, ct)
+		}
+
+		var given Response
+		if err := msgpack.NewDecoder(w.Body).UseJSONTag(true).Decode(&given); err != nil {
+			t.Fatalf("TestFormatters_MessagePack (%d): %v", i, err)
+		} else if !reflect.DeepEqual(given, tt.want) {
+			t.Fatalf("TestFormatters_MessagePack (%d): Expected %#v, given %#v", i, tt.want, given)
+		}
+	}
+}
+
+func TestNegotiateFormatter(t *testing.T) {
+	tests := []struct {
+		accept      string
+		contentType string
+	}{
+		{accept: "", contentType: "application/json"},
+		{accept: "*/*", contentType: "application/json"},
+		{accept: "application/json", contentType: "application/json"},
+		{accept: "application/xml", contentType: "application/xml"},
+		{accept: "text/html, application/xml;q=0.9, */*;q=0.8", contentType: "application/xml"},
+		{accept: "application/xml;q=0.5, application/json", contentType: "application/json"},
+		{accept: "application/msgpack", contentType: "application/msgpack"},
+		{accept: "text/html", contentType: "application/json"},
+		{accept: "application/xml;q=0.5, */*", contentType: "application/json"},
+	}
+
+	for i, tt := range tests {
+		r, _ := http.NewRequest("GET", "/", nil)
+		if tt.accept != "" {
+			r.Header.Set("Accept", tt.accept)
+		}
+
+		w := httptest.NewRecorder()
+		Success(nil).SendFormat(w, NegotiateFormatter(r))
+		if ct := w.Header().Get("Content-Type"); ct != tt.contentType {
+			t.Fatalf("TestNegotiateFormatter (%d): Expected %s, given %s", i, tt.contentType, ct)
+		}
+	}
+}
+
+func TestJSONP(t *testing.T) {
+	tests := []struct {
+		callback    string
+		contentType string
+		want        string
+	}{
+		{callback: "handle", contentType: "application/javascript", want: `/**/handle({"success":true,"result":"ok"});`},
+		{callback: "jQuery.cb_1", contentType: "application/javascript", want: `/**/jQuery.cb_1({"success":true,"result":"ok"});`},
+		{contentType: "application/json", want: `{"success":true,"result":"ok"}` + "\n"},
+		{callback: "alert(1)//", contentType: "application/json", want: `{"success":true,"result":"ok"}` + "\n"},
+		{callback: "1abc", contentType: "application/json", want: `{"success":true,"result":"ok"}` + "\n"},
+	}
+
+	for i, tt := range tests {
+		r, _ := http.NewRequest("GET", "/", nil)
+		if tt.callback != "" {
+			r.URL.RawQuery = url.Values{"callback": {tt.callback}}.Encode()
+		}
+
+		w := httptest.NewRecorder()
+		Success("ok").SendFormat(w, JSONP(r))
+		if ct := w.Header().Get("Content-Type"); ct != tt.contentType {
+			t.Fatalf("TestJSONP (%d): Expected Content-Type %s, given %s", i, tt.contentType, ct)
+		} else if w.Body.String() != tt.want {
+			t.Fatalf("TestJSONP (%d): Expected %s, given %s", i, tt.want, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// WritePaginationLinks sets an RFC 5988 Link header with rel="next" and
+// rel="prev" URLs for offset-based pagination. The URLs are built from the
+// current request URL with the offset and limit query params adjusted.
+// prev is omitted on the first page and next is omitted on the last page.
+func WritePaginationLinks(w http.ResponseWriter, r *http.Request, p Paging) {
+	if p.Limit <= 0 {
+		return
+	}
+
+	var links []string
+	if p.Offset+p.Limit < p.Count {
+		links = append(links, paginationLink(r, p.Offset+p.Limit, p.Limit, "next"))
+	}
+	if p.Offset > 0 {
+		prev := p.Offset - p.Limit
+		if prev < 0 {
+			prev = 0
+		}
+		links = append(links, paginationLink(r, prev, p.Limit, "prev"))
+	}
+
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
+
+// paginationLink returns a single Link header value for rel.
+func paginationLink(r *http.Request, offset int, limit int, rel string) string {
+	u := *r.URL
+	q := u.Query()
+	q.Set("offset", strconv.Itoa(offset))
+	q.Set("limit", strconv.Itoa(limit))
+	u.RawQuery = q.Encode()
+
+	return "<" + u.RequestURI() + `>; rel="` + rel + `"`
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWritePaginationLinks(t *testing.T) {
+	tests := []struct {
+		url    string
+		paging Paging
+		want   string
+	}{
+		{
+			// First page
+			url:    "/users?sort=name",
+			paging: Paging{Count: 50, Limit: 20, Offset: 0},
+			want:   `</users?limit=20&offset=20&sort=name>; rel="next"`,
+		},
+		{
+			// Middle page
+			url:    "/users?limit=20&offset=20",
+			paging: Paging{Count: 50, Limit: 20, Offset: 20},
+			want:   `</users?limit=20&offset=40>; rel="next", </users?limit=20&offset=0>; rel="prev"`,
+		},
+		{
+			// Last page
+			url:    "/users?limit=20&offset=40",
+			paging: Paging{Count: 50, Limit: 20, Offset: 40},
+			want:   `</users?limit=20&offset=20>; rel="prev"`,
+		},
+		{
+			// Prev offset is clamped to 0
+			url:    "/users?offset=10",
+			paging: Paging{Count: 50, Limit: 20, Offset: 10},
+			want:   `</users?limit=20&offset=30>; rel="next", </users?limit=20&offset=0>; rel="prev"`,
+		},
+		{
+			// Single page
+			url:    "/users",
+			paging: Paging{Count: 5, Limit: 20},
+		},
+	}
+
+	for i, tt := range tests {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", tt.url, nil)
+		WritePaginationLinks(w, r, tt.paging)
+
+		if given := w.Header().Get("Link"); given != tt.want {
+			t.Errorf("TestWritePaginationLinks (%d): Expected %q, given %q", i, tt.want, given)
+		}
+	}
+}
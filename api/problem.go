@@ -0,0 +1,94 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Problem is an RFC 7807 problem details document. Code and Errors are
+// extension members holding the first error's type and the individual
+// API errors.
+type Problem struct {
+	Type     string  `json:"type,omitempty"`
+	Title    string  `json:"title,omitempty"`
+	Status   int     `json:"status,omitempty"`
+	Detail   string  `json:"detail,omitempty"`
+	Instance string  `json:"instance,omitempty"`
+	Code     string  `json:"code,omitempty"`
+	Errors   []Error `json:"errors,omitempty"`
+}
+
+var _ Sender = Problem{}
+
+// ProblemFromErrors converts a status code and errors to a Problem. The
+// title is the status text, and the detail and code are the first error's
+// message and type so errors with the same status can be told apart.
+func ProblemFromErrors(status int, errs []Error) Problem {
+	p := Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Errors: errs,
+	}
+	if len(errs) > 0 {
+		p.Detail = errs[0].Message
+		p.Code = errs[0].Type
+	}
+	return p
+}
+
+// ErrorsFromProblem converts a Problem to a status code and errors. If the
+// problem has no errors, a single error is created from its code and
+// detail.
+// A missing status defaults to 400 Bad Request.
+func ErrorsFromProblem(p Problem) (int, []Error) {
+	status := p.Status
+	if status == 0 {
+		status = http.StatusBadRequest
+	}
+	if len(p.Errors) > 0 {
+		return status, p.Errors
+	}
+
+	typ := p.Code
+	if typ == "" {
+		typ = p.Type
+	}
+	if typ == "" || typ == "about:blank" {
+		typ = Failure(status).Code
+	}
+	return status, []Error{{Type: typ, Message: p.Detail}}
+}
+
+// ProblemJSON formats error responses as RFC 7807 problem details and
+// writes them as application/problem+json. The first error's message is
+// used as the detail and its type as the code extension; the errors
+// extension is only included when there are multiple errors or the error
+// relates to a field. Successful
+// responses are written with the JSON formatter.
+func ProblemJSON(r *Response, w http.ResponseWriter) error {
+	if r.Success {
+		return JSON(r, w)
+	}
+
+	p := ProblemFromErrors(r.Status, r.Errors)
+	if len(p.Errors) == 1 && p.Errors[0].Field == "" {
+		p.Errors = nil
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(r.Status)
+	return json.NewEncoder(w).Encode(p)
+}
+
+// Send writes the problem as application/problem+json.
+func (p Problem) Send(w http.ResponseWriter) {
+	status := p.Status
+	if status == 0 {
+		status = http.StatusBadRequest
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(p)
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestProblem_RoundTrip(t *testing.T) {
+	errs := []Error{
+		{Field: "name", Type: "required", Message: "Name is required"},
+		{Field: "email", Type: "invalid_parameter", Message: "Email is invalid"},
+	}
+
+	p := ProblemFromErrors(http.StatusUnprocessableEntity, errs)
+	if p.Title != "Unprocessable Entity" || p.Status != 422 || p.Detail != "Name is required" {
+		t.Fatalf("unexpected problem: %#v", p)
+	}
+
+	w := httptest.NewRecorder()
+	p.Send(w)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	} else if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Fatalf("unexpected content type: %s", ct)
+	}
+
+	var decoded Problem
+	if err := json.NewDecoder(w.Body).Decode(&decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	status, given := ErrorsFromProblem(decoded)
+	if status != http.StatusUnprocessableEntity {
+		t.Fatalf("unexpected status: %d", status)
+	} else if !reflect.DeepEqual(given, errs) {
+		t.Fatalf("unexpected errors: %#v", given)
+	}
+}
+
+func TestErrorsFromProblem_Code(t *testing.T) {
+	p := ProblemFromErrors(http.StatusConflict, []Error{{Type: "already_exists", Message: "User already exists"}})
+	p.Errors = nil
+
+	status, errs := ErrorsFromProblem(p)
+	if status != http.StatusConflict {
+		t.Fatalf("unexpected status: %d", status)
+	} else if !reflect.DeepEqual(errs, []Error{{Type: "already_exists", Message: "User already exists"}}) {
+		t.Fatalf("unexpected errors: %#v", errs)
+	}
+}
+
+func TestErrorsFromProblem_Detail(t *testing.T) {
+	status, errs := ErrorsFromProblem(Problem{Status: http.StatusNotFound, Detail: "User not found"})
+	if status != http.StatusNotFound {
+		t.Fatalf("unexpected status: %d", status)
+	} else if !reflect.DeepEqual(errs, []Error{{Type: "not_found", Message: "User not found"}}) {
+		t.Fatalf("unexpected errors: %#v", errs)
+	}
+}
+
+func TestProblemJSON(t *testing.T) {
+	tests := []struct {
+		response *ErrorResponse
+		want     string
+	}{
+		{
+			response: Failure(http.StatusUnprocessableEntity, Error{Field: "email", Type: "invalid_parameter", Message: "Email is invalid"}),
+			want:     `{"type":"about:blank","title":"Unprocessable Entity","status":422,"detail":"Email is invalid","code":"invalid_parameter","errors":[{"field":"email","type":"invalid_parameter","message":"Email is invalid"}]}`,
+		},
+		{
+			response: Failure(http.StatusConflict, Error{Type: "already_exists", Message: "A user with that email address already exists"}),
+			want:     `{"type":"about:blank","title":"Conflict","status":409,"detail":"A user with that email address already exists","code":"already_exists"}`,
+		},
+	}
+
+	for i, tt := range tests {
+		w := httptest.NewRecorder()
+		tt.response.SendFormat(w, ProblemJSON)
+
+		if w.Code != tt.response.Status {
+			t.Fatalf("TestProblemJSON (%d): Expected status %d, given %d", i, tt.response.Status, w.Code)
+		} else if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+			t.Fatalf("TestProblemJSON (%d): unexpected Content-Type: %s", i, ct)
+		} else if w.Body.String() != tt.want+"\n" {
+			t.Fatalf("TestProblemJSON (%d): Expected %s, given %s", i, tt.want, w.Body.String())
+		}
+	}
+}
@@ -29,6 +29,9 @@ type Response struct {
 
 	// Pagination info
 	Pagination *Paging `json:"paging,omitempty" xml:"paging,omitempty"`
+
+	// headers holds additional headers to set when sending.
+	headers http.Header
 }
 
 var _ Sender = &Response{}
@@ -48,16 +51,88 @@ func Success(result interface{}) *Response {
 	}
 }
 
-// Send passes the response off to the formatter and writes it.
+// ItemResult is the outcome of a single item in a bulk operation.
+type ItemResult struct {
+	XMLName xml.Name `xml:"item" json:"-"`
+
+	// Status is the status code for the item.
+	Status int `json:"status" xml:"status"`
+
+	// Error holds the error if the item failed.
+	Error *Error `json:"error,omitempty" xml:"error,omitempty"`
+
+	// Result holds the data for the item if it succeeded.
+	Result interface{} `json:"result,omitempty" xml:"result,omitempty"`
+}
+
+// MultiStatus creates a 207 Multi-Status response holding the outcome of
+// each item in a bulk operation. The response is only successful if no
+// item has an error.
+func MultiStatus(results []ItemResult) *Response {
+	success := true
+	for _, r := range results {
+		if r.Error != nil {
+			success = false
+			break
+		}
+	}
+
+	return &Response{
+		Success: success,
+		Status:  http.StatusMultiStatus,
+		Result:  results,
+	}
+}
+
+// NotModified returns a Sender that writes a 304 Not Modified with no
+// body, for handlers that do their own freshness checks. Validators such
+// as ETag and Last-Modified set on the response are preserved.
+func NotModified() Sender {
+	return notModified{}
+}
+
+// notModified is the Sender returned by NotModified.
+type notModified struct{}
+
+// Send writes the 304 status code.
+func (notModified) Send(w http.ResponseWriter) {
+	w.Header().Del("Content-Type")
+	w.Header().Del("Content-Length")
+	w.WriteHeader(http.StatusNotModified)
+}
+
+// Send passes the response off to the formatter and writes it. The
+// formatter set for the request by middleware.EnforceFormat is used
+// if there is one, otherwise Formatter.
 func (r *Response) Send(w http.ResponseWriter) {
-	Formatter(r, w)
+	r.writeHeaders(w)
+	writerFormatter(w)(r, w)
 }
 
 // SendFormat sends the response using a given formatter
 func (r *Response) SendFormat(w http.ResponseWriter, f FormatterFn) {
+	r.writeHeaders(w)
 	f(r, w)
 }
 
+// WithHeader adds a header to set on the http.ResponseWriter when the
+// response is sent. The formatter's Content-Type always takes precedence.
+func (r *Response) WithHeader(key, value string) *Response {
+	if r.headers == nil {
+		r.headers = make(http.Header)
+	}
+	r.headers.Add(key, value)
+	return r
+}
+
+// writeHeaders copies any headers added with WithHeader to w. They are
+// written before the formatter runs so the formatter can override them.
+func (r *Response) writeHeaders(w http.ResponseWriter) {
+	for k, v := range r.headers {
+		w.Header()[k] = v
+	}
+}
+
 // Paging holds pagination information for the response
 type Paging struct {
 	XMLName xml.Name     `xml:"paging" json:"-"`
@@ -65,6 +140,16 @@ type Paging struct {
 	Limit   int          `json:"limit" xml:"limit"`
 	Offset  int          `json:"offset" xml:"offset"`
 	Order   *PagingOrder `json:"order,omitempty" xml:"order,omitempty"`
+
+	// Next and Prev are opaque cursors for cursor-based pagination.
+	Next string `json:"next,omitempty" xml:"next,omitempty"`
+	Prev string `json:"prev,omitempty" xml:"prev,omitempty"`
+}
+
+// WithCursors returns a copy of p with the next and prev cursors set.
+func (p Paging) WithCursors(next, prev string) Paging {
+	p.Next, p.Prev = next, prev
+	return p
 }
 
 // PagingOrder is the order of the pagination.
@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"testing"
@@ -119,3 +120,66 @@ func TestResponse(t *testing.T) {
 		}
 	}
 }
+
+func TestResponse_WithHeader(t *testing.T) {
+	w := httptest.NewRecorder()
+	Success(nil).
+		WithHeader("X-Total-Count", "42").
+		WithHeader("Content-Type", "text/plain").
+		SendFormat(w, JSON)
+
+	if got := w.Header().Get("X-Total-Count"); got != "42" {
+		t.Fatalf("unexpected X-Total-Count header: %q", got)
+	} else if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("unexpected Content-Type: %q", ct)
+	}
+
+	w = httptest.NewRecorder()
+	Failure(409).WithHeader("Retry-After", "10").SendFormat(w, XML)
+
+	if got := w.Header().Get("Retry-After"); got != "10" {
+		t.Fatalf("unexpected Retry-After header: %q", got)
+	} else if w.Code != 409 {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	w := httptest.NewRecorder()
+	w.Header().Set("ETag", `"abc"`)
+	w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+	w.Header().Set("Content-Type", "application/json")
+	NotModified().Send(w)
+
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	} else if w.Body.Len() != 0 {
+		t.Fatalf("unexpected body: %q", w.Body.String())
+	} else if got := w.Header().Get("ETag"); got != `"abc"` {
+		t.Fatalf("unexpected ETag header: %q", got)
+	} else if got := w.Header().Get("Last-Modified"); got != "Mon, 02 Jan 2006 15:04:05 GMT" {
+		t.Fatalf("unexpected Last-Modified header: %q", got)
+	} else if ct := w.Header().Get("Content-Type"); ct != "" {
+		t.Fatalf("unexpected Content-Type: %q", ct)
+	}
+}
+
+func TestMultiStatus(t *testing.T) {
+	Formatter = JSON
+
+	type user struct {
+		ID int `json:"id"`
+	}
+
+	w := httptest.NewRecorder()
+	MultiStatus([]ItemResult{
+		{Status: 201, Result: user{ID: 1}},
+		{Status: 422, Error: &Error{Field: "name", Type: "required", Message: "Required field missing"}},
+	}).Send(w)
+
+	if w.Code != 207 {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	} else if w.Body.String() != `{"success":false,"status":207,"result":[{"status":201,"result":{"id":1}},{"status":422,"error":{"field":"name","type":"required","message":"Required field missing"}}]}`+"\n" {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+}
@@ -0,0 +1,175 @@
+package validator
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/cristiangraz/kumi/api"
+	"github.com/ghodss/yaml"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// Format converts request bodies of the given media types to JSON so they
+// can be validated against a JSON schema.
+type Format struct {
+	// MediaTypes are the Content-Types handled by the format.
+	MediaTypes []string
+
+	// ToJSON converts the body to JSON. If nil, the body is already JSON.
+	ToJSON func(body []byte) ([]byte, error)
+}
+
+var (
+	// JSON is the Format for JSON request bodies.
+	JSON = Format{
+		MediaTypes: []string{"application/json"},
+	}
+
+	// YAML is the Format for YAML request bodies.
+	YAML = Format{
+		MediaTypes: []string{"application/yaml", "application/x-yaml", "text/yaml"},
+		ToJSON:     yaml.YAMLToJSON,
+	}
+)
+
+// NewMultiFormat returns a new Validator that validates request bodies in
+// any of the given formats against one schema. Use ValidRequest to select
+// the format using the request's Content-Type. If no formats are given,
+// only JSON is accepted.
+func NewMultiFormat(schema gojsonschema.JSONLoader, options *Options, limit int64, formats ...Format) *Validator {
+	v := New(schema, options, limit)
+	v.formats = formats
+
+	return v
+}
+
+// ValidRequest validates the request body using the Format matching the
+// request's Content-Type. If no Format matches, or the Content-Type is not
+// in Options.RequireContentType, Options.InvalidContentType is returned
+// (or Options.BadRequest if it is not set). If the body cannot be
+// converted to JSON, Options.InvalidBody is returned (or
+// Options.InvalidJSON if it is not set). The limit applies to the body as
+// sent, before it is converted to JSON. If Options.DecodeContentEncoding
+// is set, the body is decoded according to its Content-Encoding first.
+func (v *Validator) ValidRequest(r *http.Request, dst interface{}) api.Sender {
+	if dst == nil {
+		panic("dst required")
+	}
+
+	f, ok := v.format(r.Header.Get("Content-Type"))
+	if !ok || !v.contentTypeAllowed(r.Header.Get("Content-Type")) {
+		r.Body.Close()
+		return v.invalidContentType()
+	}
+	defer r.Body.Close()
+
+	body := requestBody(r)
+	if v.Options.DecodeContentEncoding {
+		gz, sender := v.decodeContentEncoding(body, r.Header.Get("Content-Encoding"))
+		if sender != nil {
+			return sender
+		}
+		body = gz
+	}
+
+	limit := v.limit()
+	if f.ToJSON == nil {
+		return v.valid(r, body, dst, limit)
+	}
+
+	b, err := ioutil.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return v.Options.BadRequest
+	} else if len(b) == 0 {
+		return v.Options.RequestBodyRequired
+	} else if int64(len(b)) > limit {
+		return v.Options.RequestBodyExceeded
+	}
+
+	j, err := f.ToJSON(b)
+	if err != nil {
+		return v.invalidBody()
+	}
+	return v.validBytes(r, j, dst)
+}
+
+// bufferedBody is implemented by request bodies that have already been
+// read into memory, such as those buffered by kumi.BufferBody.
+type bufferedBody interface {
+	BufferedBody() []byte
+}
+
+// requestBody returns a reader for the request body. A buffered body is
+// read from the start even if another reader has already consumed r.Body.
+func requestBody(r *http.Request) io.Reader {
+	return rewind(r.Body)
+}
+
+// rewind returns a reader over the buffered bytes if body is buffered,
+// otherwise body itself.
+func rewind(body io.Reader) io.Reader {
+	if bb, ok := body.(bufferedBody); ok {
+		return bytes.NewReader(bb.BufferedBody())
+	}
+	return body
+}
+
+// format returns the Format for a Content-Type header.
+func (v *Validator) format(contentType string) (Format, bool) {
+	formats := v.formats
+	if len(formats) == 0 {
+		formats = []Format{JSON}
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return Format{}, false
+	}
+	for _, f := range formats {
+		for _, mt := range f.MediaTypes {
+			if mt == mediaType {
+				return f, true
+			}
+		}
+	}
+	return Format{}, false
+}
+
+// contentTypeAllowed reports whether contentType is in
+// Options.RequireContentType, or true if the option is not set.
+func (v *Validator) contentTypeAllowed(contentType string) bool {
+	if len(v.Options.RequireContentType) == 0 {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, ct := range v.Options.RequireContentType {
+		if strings.EqualFold(ct, mediaType) {
+			return true
+		}
+	}
+	return false
+}
+
+// invalidContentType returns the error for an unsupported Content-Type.
+func (v *Validator) invalidContentType() api.Sender {
+	if v.Options.InvalidContentType.StatusCode == 0 {
+		return v.Options.BadRequest
+	}
+	return v.Options.InvalidContentType
+}
+
+// invalidBody returns the error for a body that cannot be converted to JSON.
+func (v *Validator) invalidBody() api.Sender {
+	if v.Options.InvalidBody.StatusCode == 0 {
+		return v.Options.InvalidJSON
+	}
+	return v.Options.InvalidBody
+}
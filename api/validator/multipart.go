@@ -0,0 +1,83 @@
+package validator
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/cristiangraz/kumi/api"
+)
+
+// defaultMaxMultipartSize is the maximum size of a multipart request if
+// Options.MaxMultipartSize is not set.
+const defaultMaxMultipartSize = 32 << 20
+
+// ValidMultipart parses a multipart/form-data request and validates the
+// JSON in the form field named jsonField against the schema. The field
+// may be sent as a regular form value or as a file part. If the JSON is
+// valid, dst is populated and the parsed form is returned so the handler
+// can access the file parts.
+//
+// The validator's limit applies to the JSON field and is used as the
+// maximum memory for parsing the form; larger file parts are stored in
+// temporary files. The whole request is limited to
+// Options.MaxMultipartSize. The temporary files are removed if validation fails,
+// otherwise the caller is responsible for calling RemoveAll on the form.
+func (v *Validator) ValidMultipart(r *http.Request, jsonField string, dst interface{}) (*multipart.Form, api.Sender) {
+	if dst == nil {
+		panic("dst required")
+	}
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" {
+		r.Body.Close()
+		return nil, v.invalidContentType()
+	}
+
+	maxSize := v.Options.MaxMultipartSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxMultipartSize
+	}
+	r.Body = http.MaxBytesReader(nil, r.Body, maxSize)
+
+	limit := v.limit()
+	if err := r.ParseMultipartForm(limit); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return nil, v.Options.RequestBodyExceeded
+		}
+		return nil, v.Options.BadRequest
+	}
+	form := r.MultipartForm
+
+	body, err := multipartField(form, jsonField)
+	if err != nil {
+		form.RemoveAll()
+		return nil, v.Options.BadRequest
+	} else if body == nil {
+		form.RemoveAll()
+		return nil, v.Options.RequestBodyRequired
+	}
+	defer body.Close()
+
+	if sender := v.valid(r, body, dst, limit); sender != nil {
+		form.RemoveAll()
+		return nil, sender
+	}
+	return form, nil
+}
+
+// multipartField opens the form value or file part named name. A nil
+// ReadCloser is returned if the field is missing or empty.
+func multipartField(form *multipart.Form, name string) (io.ReadCloser, error) {
+	if values := form.Value[name]; len(values) > 0 && values[0] != "" {
+		return ioutil.NopCloser(strings.NewReader(values[0])), nil
+	} else if files := form.File[name]; len(files) > 0 && files[0].Size > 0 {
+		return files[0].Open()
+	}
+	return nil, nil
+}
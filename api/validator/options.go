@@ -4,6 +4,7 @@ import (
 	"errors"
 
 	"github.com/cristiangraz/kumi/api"
+	"github.com/xeipuuv/gojsonschema"
 )
 
 // Options defines validation rules for validating requests.
@@ -14,11 +15,32 @@ type Options struct {
 	BadRequest          api.Error
 	Rules               Rules
 
+	// InvalidContentType is returned by ValidRequest and ValidMultipart
+	// when the request's Content-Type is not supported. Optional; if left
+	// empty BadRequest is used.
+	InvalidContentType api.Error
+
+	// RequireContentType restricts the media types ValidRequest accepts
+	// (i.e. "application/json"). A request with a missing Content-Type or
+	// one not in the list receives InvalidContentType. If empty, any
+	// Content-Type matching one of the Validator's formats is accepted.
+	RequireContentType []string
+
+	// InvalidBody is returned by ValidRequest when a non-JSON body, such
+	// as YAML, cannot be converted to JSON. Optional; if left empty
+	// InvalidJSON is used.
+	InvalidBody api.Error
+
 	// Limit is used to create an io.LimitReader when reading the request
 	// body. Consider this the global maximum... each validator can contain
 	// a specific limit that will override this value.
 	Limit int64
 
+	// MaxMultipartSize is the maximum total size of a multipart/form-data
+	// request read by ValidMultipart, including any file parts. Larger
+	// requests receive RequestBodyExceeded. If zero, 32 MB is used.
+	MaxMultipartSize int64
+
 	// ErrorStatus is the status code to use in the response for schema errors.
 	// If left empty a 400 Bad Request code will be used.
 	ErrorStatus int
@@ -26,6 +48,43 @@ type Options struct {
 	// Swapper swaps json schema errors for api errors. If none is provided,
 	// the Swap function in this package will be used.
 	Swapper Swapper
+
+	// SwapperWithContext swaps json schema errors for api errors with
+	// access to the request. It is used instead of Swapper by the methods
+	// that receive a request: ValidRequest, ValidMultipart and ValidQuery.
+	SwapperWithContext SwapperWithContext
+
+	// KeepCompositeErrors keeps oneOf/anyOf/allOf errors alongside the
+	// more specific errors for the failing branches. By default they are
+	// dropped when other errors are present. Only used by the default
+	// Swapper, Swap; a custom Swapper or SwapperWithContext receives every
+	// error and decides which to keep.
+	KeepCompositeErrors bool
+
+	// MaxErrors limits the number of errors returned for an invalid
+	// document. When there are more, the first MaxErrors errors are
+	// returned followed by an errors_omitted error. If zero, all errors
+	// are returned.
+	MaxErrors int
+
+	// UseNumber decodes JSON numbers into interface{} values of dst as
+	// json.Number instead of float64, so integers larger than 2^53 keep
+	// their precision. Any map[string]interface{} or interface{} in dst
+	// must then handle json.Number. Typed numeric fields are unaffected.
+	UseNumber bool
+
+	// DecodeContentEncoding makes ValidRequest decompress request bodies
+	// sent with a Content-Encoding: gzip header. The limit applies to the
+	// decompressed body. Bodies with any other encoding receive
+	// UnsupportedContentEncoding. Valid and ValidStream only receive the
+	// body, so they never decode it.
+	DecodeContentEncoding bool
+
+	// UnsupportedContentEncoding is returned by ValidRequest when
+	// DecodeContentEncoding is set and the request's Content-Encoding is
+	// not supported, i.e. a 415 Unsupported Media Type. Optional; if left
+	// empty BadRequest is used.
+	UnsupportedContentEncoding api.Error
 }
 
 var (
@@ -52,3 +111,9 @@ func (o Options) Valid() error {
 	}
 	return nil
 }
+
+// NewValidator returns a new Validator using these options.
+// See New for more details.
+func (o *Options) NewValidator(schema gojsonschema.JSONLoader, limit int64) *Validator {
+	return New(schema, o, limit)
+}
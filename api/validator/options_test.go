@@ -1,6 +1,10 @@
 package validator
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/xeipuuv/gojsonschema"
+)
 
 func TestValidatorOptionsValid(t *testing.T) {
 	tests := []struct {
@@ -59,3 +63,17 @@ func TestValidatorOptionsValid(t *testing.T) {
 		}
 	}
 }
+
+func TestOptions_NewValidator(t *testing.T) {
+	userSchema := gojsonschema.NewStringLoader(`{"type": "object", "required": ["name"]}`)
+	accountSchema := gojsonschema.NewStringLoader(`{"type": "object", "required": ["id"]}`)
+
+	users := validatorOpts.NewValidator(userSchema, 10)
+	accounts := validatorOpts.NewValidator(accountSchema, 0)
+
+	if users.Options != validatorOpts || accounts.Options != validatorOpts {
+		t.Fatal("TestOptions_NewValidator: Expected validators to share options")
+	} else if users.Limit != 10 || accounts.Limit != 0 {
+		t.Fatalf("TestOptions_NewValidator: Unexpected limits %d, %d", users.Limit, accounts.Limit)
+	}
+}
@@ -0,0 +1,114 @@
+package validator
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/cristiangraz/kumi/api"
+)
+
+// ValidQuery validates the request's query string against the schema. A
+// JSON object is built from r.URL.Query() and validated the same way as a
+// request body, so the same Rules and Options apply. If the query string
+// is valid, dst is populated.
+//
+// Query string values are always strings, so each value is coerced using
+// the type of the matching property in the schema:
+//
+//   - "integer" and "number" values are parsed as numbers, so ?age=30
+//     becomes {"age": 30} for {"age": {"type": "integer"}}.
+//   - "boolean" values are parsed with strconv.ParseBool, so ?active=1
+//     becomes {"active": true}.
+//   - "array" values hold every value for the key, coerced using the
+//     type of "items", so ?id=1&id=2 becomes {"id": [1, 2]}.
+//
+// Values that cannot be coerced are left as strings so the schema reports
+// an invalid_type error. Keys with more than one value that are not arrays
+// in the schema use the first value. Properties without a type, and keys
+// not in the schema, are left as strings.
+func (v *Validator) ValidQuery(r *http.Request, dst interface{}) api.Sender {
+	if dst == nil {
+		panic("dst required")
+	}
+
+	properties, err := v.queryProperties()
+	if err != nil {
+		return v.Options.BadRequest // An error with the schema
+	}
+
+	b, err := json.Marshal(coerceQuery(r.URL.Query(), properties))
+	if err != nil {
+		return v.Options.BadRequest
+	}
+	return v.validBytes(r, b, dst)
+}
+
+// queryProperties returns the top-level "properties" of the schema. The
+// schema is only loaded on the first call.
+func (v *Validator) queryProperties() (map[string]interface{}, error) {
+	v.queryOnce.Do(func() {
+		schema, err := v.Schema.LoadJSON()
+		if err != nil {
+			v.queryPropsErr = err
+			return
+		}
+
+		m, _ := schema.(map[string]interface{})
+		v.queryProps, _ = m["properties"].(map[string]interface{})
+	})
+	return v.queryProps, v.queryPropsErr
+}
+
+// coerceQuery builds a JSON document from query string values using the
+// property types in the schema.
+func coerceQuery(query url.Values, properties map[string]interface{}) map[string]interface{} {
+	doc := make(map[string]interface{}, len(query))
+	for k, values := range query {
+		if len(values) == 0 {
+			continue
+		}
+
+		property, _ := properties[k].(map[string]interface{})
+		if schemaType(property) != "array" {
+			doc[k] = coerceValue(values[0], schemaType(property))
+			continue
+		}
+
+		items, _ := property["items"].(map[string]interface{})
+		arr := make([]interface{}, len(values))
+		for i, v := range values {
+			arr[i] = coerceValue(v, schemaType(items))
+		}
+		doc[k] = arr
+	}
+	return doc
+}
+
+// coerceValue converts a query string value to the given JSON schema type.
+// If the value cannot be converted, it is returned as a string.
+func coerceValue(v string, typ string) interface{} {
+	switch typ {
+	case "integer":
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return v
+}
+
+// schemaType returns the "type" of a schema property, or "" if the
+// property is missing or has no single type.
+func schemaType(property map[string]interface{}) string {
+	typ, _ := property["type"].(string)
+	return typ
+}
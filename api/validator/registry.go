@@ -0,0 +1,70 @@
+package validator
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// Registry tracks validators so every schema can be compiled at startup,
+// failing fast if any schema is invalid.
+type Registry struct {
+	mu         sync.Mutex
+	names      []string
+	validators []*Validator
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds v to the registry under name and returns v, so it can
+// be used when declaring the validator:
+//
+//	var userValidator = registry.Register("user", validator.New(schema, opts, 0))
+func (r *Registry) Register(name string, v *Validator) *Validator {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.names = append(r.names, name)
+	r.validators = append(r.validators, v)
+	return v
+}
+
+// Compile compiles the schema of every registered validator in the order
+// they were registered. The first error is returned along with the name
+// of its schema. Compiled schemas are reused for validation, so Compile
+// should be called before serving requests, i.e. with Engine.OnValidate.
+func (r *Registry) Compile() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, v := range r.validators {
+		if err := v.Compile(); err != nil {
+			return fmt.Errorf("validator: schema %q: %s", r.names[i], err)
+		}
+	}
+	return nil
+}
+
+// Compile compiles the validator's schema so it is not parsed again for
+// each request. It must not be called while requests are being validated.
+func (v *Validator) Compile() error {
+	s, err := gojsonschema.NewSchema(v.Schema)
+	if err != nil {
+		return err
+	}
+	v.compiled = s
+	return nil
+}
+
+// validate validates document against the compiled schema, if there is
+// one, otherwise against v.Schema.
+func (v *Validator) validate(document gojsonschema.JSONLoader) (*gojsonschema.Result, error) {
+	if v.compiled != nil {
+		return v.compiled.Validate(document)
+	}
+	return gojsonschema.Validate(v.Schema, document)
+}
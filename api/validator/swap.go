@@ -25,7 +25,14 @@ var rxNestedFields = regexp.MustCompile(`\.[0-9]+$`)
 
 // Swap takes json schema errors and swaps them for an array of
 // api errors based on mapping rules.
-func Swap(errors []gojsonschema.ResultError, rules Rules) (e []api.Error) {
+func Swap(errors []gojsonschema.ResultError, rules Rules) []api.Error {
+	return swapErrors(errors, rules, false)
+}
+
+// swapErrors swaps json schema errors for api errors. When there are
+// other errors, oneOf/anyOf/allOf errors are skipped unless keepComposite
+// is true.
+func swapErrors(errors []gojsonschema.ResultError, rules Rules, keepComposite bool) (e []api.Error) {
 	count := len(errors)
 	used := map[string]bool{}
 	for _, err := range errors {
@@ -71,7 +78,7 @@ func Swap(errors []gojsonschema.ResultError, rules Rules) (e []api.Error) {
 
 		// The validation failed against oneOf/anyOf/allOf validation, but more errors are returned.
 		// Skip returning this error in favor of the other more specific errors.
-		if count > 1 && (errType == "number_one_of" || errType == "number_any_of" || errType == "number_all_of") {
+		if !keepComposite && count > 1 && (errType == "number_one_of" || errType == "number_any_of" || errType == "number_all_of") {
 			continue
 		}
 
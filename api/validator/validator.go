@@ -2,10 +2,14 @@ package validator
 
 import (
 	"bytes"
+	"compress/gzip"
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
+	"reflect"
+	"strings"
 	"sync"
 
 	"github.com/cristiangraz/kumi/api"
@@ -15,6 +19,11 @@ import (
 // Swapper swaps json schema errors for api errors.
 type Swapper func(errors []gojsonschema.ResultError, rules Rules) []api.Error
 
+// SwapperWithContext swaps json schema errors for api errors using the
+// request being validated, i.e. to localize messages using the
+// Accept-Language header.
+type SwapperWithContext func(r *http.Request, errors []gojsonschema.ResultError, rules Rules) []api.Error
+
 // Validator is a JSON schema validator. It holds a JSON schema,
 // pointer to a Validator, and optional limit for an io.LimitReader.
 type Validator struct {
@@ -22,6 +31,13 @@ type Validator struct {
 	Options   *Options
 	Limit     int64
 	secondary SecondaryValidator
+	formats   []Format
+	compiled  *gojsonschema.Schema
+
+	// queryOnce loads the schema's top-level properties for ValidQuery.
+	queryOnce     sync.Once
+	queryProps    map[string]interface{}
+	queryPropsErr error
 }
 
 // SecondaryValidator allows for custom validation logic if the document
@@ -63,6 +79,13 @@ func NewSecondary(schema gojsonschema.JSONLoader, options *Options, limit int64,
 //
 // If the contents of the reader are valid, dst will be populated.
 // If r implements io.ReadCloser, the reader will be closed.
+//
+// If r is a body buffered by kumi.BufferBody, the buffered bytes are
+// validated even if another reader has already consumed it.
+//
+// Once the body has been read within the limit, it is validated with
+// ValidBytes. Schema errors are converted with Options.Swapper, so a
+// custom Swapper applies to Valid as well as the request methods.
 func (v *Validator) Valid(r io.Reader, dst interface{}) api.Sender {
 	if dst == nil {
 		panic("dst required")
@@ -70,45 +93,55 @@ func (v *Validator) Valid(r io.Reader, dst interface{}) api.Sender {
 	if closer, ok := r.(io.ReadCloser); ok {
 		defer closer.Close()
 	}
+	r = rewind(r)
+	return v.valid(nil, r, dst, v.limit())
+}
 
-	limit := v.Options.Limit
-	if v.Limit > 0 {
-		limit = v.Limit
-	}
-
+// valid reads up to limit bytes of JSON from body and validates it.
+// req is passed to Options.SwapperWithContext and may be nil.
+func (v *Validator) valid(req *http.Request, body io.Reader, dst interface{}, limit int64) api.Sender {
 	limitReader := limitReaderPool.Get().(*io.LimitedReader)
-	limitReader.R = r
+	limitReader.R = body
 	limitReader.N = limit + 1 // extend by 1 byte, if N bytes are left to read we've hit max
 	defer limitReaderPool.Put(limitReader)
 
-	buf := new(bytes.Buffer)
-	tee := io.TeeReader(limitReader, buf)
-	if err := json.NewDecoder(tee).Decode(&dst); err != nil {
+	b, err := ioutil.ReadAll(limitReader)
+	if err != nil {
+		return v.readError(err, limitReader, limit)
+	} else if int64(len(b)) > limit {
+		return v.Options.RequestBodyExceeded
+	}
+	return v.validBytes(req, b, dst)
+}
+
+// ValidBytes validates a JSON document that has already been read against
+// the JSON schema. It behaves like Valid, but no limit is applied, so
+// callers reading the body themselves avoid a second copy of it.
+func (v *Validator) ValidBytes(body []byte, dst interface{}) api.Sender {
+	return v.validBytes(nil, body, dst)
+}
+
+// validBytes validates body. req is passed to Options.SwapperWithContext
+// and may be nil.
+func (v *Validator) validBytes(req *http.Request, body []byte, dst interface{}) api.Sender {
+	if dst == nil {
+		panic("dst required")
+	} else if len(body) == 0 {
+		return v.Options.RequestBodyRequired
+	}
+
+	if err := v.unmarshal(body, &dst); err != nil {
 		switch err.(type) {
-		case *json.SyntaxError:
-			return v.Options.InvalidJSON
 		case *json.UnmarshalTypeError:
 			// Do nothing. Let the validator catch it below so that the API caller
 			// receives specific feedback on the error.
 		default:
-			switch err {
-			case io.ErrUnexpectedEOF, io.EOF:
-				if limitReader.N == 0 { // Nothing left to read on io.LimitedReader, body exceeded
-					return v.Options.RequestBodyExceeded
-				} else if limitReader.N == limit+1 { // Empty body
-					return v.Options.RequestBodyRequired
-				}
-				return v.Options.InvalidJSON
-			default:
-				return v.Options.InvalidJSON
-			}
+			return v.Options.InvalidJSON
 		}
 	}
 
-	body := buf.String()
-
-	document := gojsonschema.NewStringLoader(body)
-	result, err := gojsonschema.Validate(v.Schema, document)
+	document := gojsonschema.NewBytesLoader(body)
+	result, err := v.validate(document)
 	if err != nil {
 		switch err.(type) {
 		case *json.SyntaxError:
@@ -130,13 +163,158 @@ func (v *Validator) Valid(r io.Reader, dst interface{}) api.Sender {
 		}
 	}
 
-	e := Swap(result.Errors(), v.Options.Rules)
-	statusCode := http.StatusBadRequest
+	return api.Failure(v.errorStatus(), v.swap(req, result.Errors())...)
+}
+
+// unmarshal decodes body into dst, using json.Number for numbers if
+// Options.UseNumber is set.
+func (v *Validator) unmarshal(body []byte, dst interface{}) error {
+	if !v.Options.UseNumber {
+		return json.Unmarshal(body, dst)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	return dec.Decode(dst)
+}
+
+// ValidStream validates a JSON array one element at a time so large
+// documents never need to be held in memory. Each element is validated
+// against itemSchema and passed to fn along with an api.Sender holding
+// any errors (or nil if the element is valid). Returning false from fn
+// stops reading the stream.
+//
+// The Validator's Options and limit apply to the stream as a whole.
+// An api.Sender is returned if the body is not a JSON array or cannot
+// be read.
+func (v *Validator) ValidStream(r io.Reader, itemSchema gojsonschema.JSONLoader, fn func(index int, dst json.RawMessage, sender api.Sender) bool) api.Sender {
+	if closer, ok := r.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+
+	schema, err := gojsonschema.NewSchema(itemSchema)
+	if err != nil {
+		return v.Options.BadRequest // An error with the schema
+	}
+
+	limit := v.limit()
+	limitReader := limitReaderPool.Get().(*io.LimitedReader)
+	limitReader.R = r
+	limitReader.N = limit + 1 // extend by 1 byte, if N bytes are left to read we've hit max
+	defer limitReaderPool.Put(limitReader)
+
+	dec := json.NewDecoder(limitReader)
+	if tok, err := dec.Token(); err != nil {
+		return v.readError(err, limitReader, limit)
+	} else if d, ok := tok.(json.Delim); !ok || d != '[' {
+		return v.Options.InvalidJSON
+	}
+
+	for i := 0; dec.More(); i++ {
+		var item json.RawMessage
+		if err := dec.Decode(&item); err != nil {
+			return v.readError(err, limitReader, limit)
+		}
+
+		var sender api.Sender
+		result, err := schema.Validate(gojsonschema.NewBytesLoader(item))
+		if err != nil {
+			sender = v.Options.InvalidJSON
+		} else if !result.Valid() {
+			sender = api.Failure(v.errorStatus(), v.swap(nil, result.Errors())...)
+		}
+
+		if !fn(i, item, sender) {
+			return nil
+		}
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return v.readError(err, limitReader, limit)
+	}
+	return nil
+}
+
+// decodeContentEncoding returns a reader that decodes r according to the
+// request's Content-Encoding header. Only gzip is supported.
+func (v *Validator) decodeContentEncoding(r io.Reader, encoding string) (io.Reader, api.Sender) {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "", "identity":
+		return r, nil
+	case "gzip", "x-gzip":
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, v.Options.BadRequest
+		}
+		return gz, nil
+	}
+
+	if v.Options.UnsupportedContentEncoding.StatusCode == 0 {
+		return nil, v.Options.BadRequest
+	}
+	return nil, v.Options.UnsupportedContentEncoding
+}
+
+// readError maps an error reading the request body to an api.Sender.
+func (v *Validator) readError(err error, limitReader *io.LimitedReader, limit int64) api.Sender {
+	switch err {
+	case io.ErrUnexpectedEOF, io.EOF:
+		if limitReader.N == 0 { // Nothing left to read on io.LimitedReader, body exceeded
+			return v.Options.RequestBodyExceeded
+		} else if limitReader.N == limit+1 { // Empty body
+			return v.Options.RequestBodyRequired
+		}
+		return v.Options.InvalidJSON
+	default:
+		return v.Options.InvalidJSON
+	}
+}
+
+// limit returns the maximum number of bytes to read from the body.
+func (v *Validator) limit() int64 {
+	if v.Limit > 0 {
+		return v.Limit
+	}
+	return v.Options.Limit
+}
+
+// errorStatus returns the status code to use for schema errors.
+func (v *Validator) errorStatus() int {
 	if v.Options.ErrorStatus > 0 {
-		statusCode = v.Options.ErrorStatus
+		return v.Options.ErrorStatus
 	}
+	return http.StatusBadRequest
+}
+
+// swap converts json schema errors to api errors using the
+// SwapperWithContext in the Options if req is set, otherwise the Swapper.
+// If there are more than Options.MaxErrors errors, the rest are replaced
+// with a single errors_omitted error.
+func (v *Validator) swap(req *http.Request, errors []gojsonschema.ResultError) []api.Error {
+	var e []api.Error
+	switch swapper := v.Options.Swapper; {
+	case req != nil && v.Options.SwapperWithContext != nil:
+		e = v.Options.SwapperWithContext(req, errors, v.Options.Rules)
+	case swapper == nil || isSwap(swapper):
+		e = swapErrors(errors, v.Options.Rules, v.Options.KeepCompositeErrors)
+	default:
+		e = swapper(errors, v.Options.Rules)
+	}
+
+	if max := v.Options.MaxErrors; max > 0 && len(e) > max {
+		omitted := len(e) - max
+		e = append(e[:max:max], api.Error{
+			Type:    "errors_omitted",
+			Message: fmt.Sprintf("%d more errors omitted", omitted),
+		})
+	}
+	return e
+}
 
-	return api.Failure(statusCode, e...)
+// isSwap reports whether fn is the package's Swap function, the default
+// Swapper that honors Options.KeepCompositeErrors.
+func isSwap(fn Swapper) bool {
+	return reflect.ValueOf(fn).Pointer() == reflect.ValueOf(Swap).Pointer()
 }
 
 var limitReaderPool = &sync.Pool{
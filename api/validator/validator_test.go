@@ -2,14 +2,19 @@ package validator
 
 import (
 	"bytes"
+	"compress/gzip"
+	"encoding/json"
 	"io/ioutil"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"strings"
 	"testing"
 
+	"github.com/cristiangraz/kumi"
 	"github.com/cristiangraz/kumi/api"
+	"github.com/cristiangraz/kumi/router"
 	"github.com/xeipuuv/gojsonschema"
 )
 
@@ -95,6 +100,49 @@ func BenchmarkValidator(b *testing.B) {
 	}
 }
 
+func BenchmarkValidator_Large(b *testing.B) {
+	schema := gojsonschema.NewStringLoader(`{
+		"type": "object",
+		"properties": {
+			"items": {
+				"type": "array",
+				"items": {
+					"type": "string"
+				}
+			}
+		},
+		"required": ["items"]
+	}`)
+
+	items := make([]string, 10000)
+	for i := range items {
+		items[i] = strings.Repeat("a", 32)
+	}
+	payload, _ := json.Marshal(map[string][]string{"items": items})
+
+	type schemaDest struct {
+		Items []string `json:"items"`
+	}
+
+	v := New(schema, validatorOpts, int64(len(payload)))
+
+	b.Run("Valid", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var dst schemaDest
+			v.Valid(bytes.NewReader(payload), &dst)
+		}
+	})
+
+	b.Run("ValidBytes", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var dst schemaDest
+			v.ValidBytes(payload, &dst)
+		}
+	})
+}
+
 func TestValidator(t *testing.T) {
 	schema := `{
         "type": "object",
@@ -530,6 +578,935 @@ func TestSecondaryValidator(t *testing.T) {
 	}
 }
 
+func TestValidStream(t *testing.T) {
+	itemSchema := gojsonschema.NewStringLoader(`{
+		"type": "object",
+		"properties": {
+			"name": {
+				"type": "string"
+			}
+		},
+		"required": ["name"],
+		"additionalProperties": false
+	}`)
+
+	payload := `[{"name": "Jon"}, {"nme": "Sally"}, {"name": "Sarah"}]`
+
+	type item struct {
+		index  int
+		body   string
+		sender api.Sender
+	}
+
+	var items []item
+	v := New(itemSchema, validatorOpts, 0)
+	sender := v.ValidStream(strings.NewReader(payload), itemSchema, func(index int, dst json.RawMessage, sender api.Sender) bool {
+		items = append(items, item{index: index, body: string(dst), sender: sender})
+		return true
+	})
+
+	if sender != nil {
+		t.Fatalf("TestValidStream: Unexpected error: %v", sender)
+	} else if len(items) != 3 {
+		t.Fatalf("TestValidStream: Expected 3 items, given %d", len(items))
+	} else if items[0].sender != nil || items[2].sender != nil {
+		t.Fatalf("TestValidStream: Expected items 0 and 2 to be valid")
+	} else if items[1].body != `{"nme": "Sally"}` {
+		t.Fatalf("TestValidStream: Unexpected item: %s", items[1].body)
+	}
+
+	expect, given := httptest.NewRecorder(), httptest.NewRecorder()
+	api.Failure(422,
+		api.Error{Field: "name", Type: RequiredError.Type, Message: "Required field missing"},
+		api.Error{Field: "nme", Type: UnknownParameterError.Type, Message: "Unknown parameter sent"},
+	).Send(expect)
+	items[1].sender.Send(given)
+
+	if !reflect.DeepEqual(expect, given) {
+		t.Fatalf("TestValidStream: Expected %s, given %s", expect.Body.String(), given.Body.String())
+	}
+
+	// Stop after the first invalid item.
+	var count int
+	v.ValidStream(strings.NewReader(payload), itemSchema, func(index int, dst json.RawMessage, sender api.Sender) bool {
+		count++
+		return sender == nil
+	})
+	if count != 2 {
+		t.Fatalf("TestValidStream: Expected stream to stop after 2 items, read %d", count)
+	}
+
+	// Document level errors.
+	tests := []struct {
+		payload string
+		expect  api.Sender
+	}{
+		{payload: ``, expect: RequestBodyRequiredError},
+		{payload: `{"name": "Jon"}`, expect: InvalidJSONError},
+		{payload: `[{"name": "Jon"}`, expect: InvalidJSONError},
+	}
+
+	for i, tt := range tests {
+		sender := v.ValidStream(strings.NewReader(tt.payload), itemSchema, func(index int, dst json.RawMessage, sender api.Sender) bool {
+			return true
+		})
+		if !reflect.DeepEqual(sender, tt.expect) {
+			t.Errorf("TestValidStream (%d): Expected %v, given %v", i, tt.expect, sender)
+		}
+	}
+}
+
+func TestValidator_DecodeContentEncoding(t *testing.T) {
+	schema := gojsonschema.NewStringLoader(`{
+		"type": "object",
+		"properties": {
+			"name": {
+				"type": "string"
+			}
+		},
+		"required": ["name"],
+		"additionalProperties": false
+	}`)
+
+	gzipped := func(s string) []byte {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		gz.Write([]byte(s))
+		gz.Close()
+		return buf.Bytes()
+	}
+
+	type schemaDest struct {
+		Name string `json:"name"`
+	}
+
+	unsupported := api.Error{StatusCode: http.StatusUnsupportedMediaType, Type: "unsupported_content_encoding", Message: "Unsupported Content-Encoding"}
+	opts := *validatorOpts
+	opts.DecodeContentEncoding = true
+	opts.UnsupportedContentEncoding = unsupported
+
+	tests := []struct {
+		payload  []byte
+		encoding string
+		limit    int64
+		expect   api.Sender
+		name     string
+	}{
+		{payload: gzipped(`{"name": "Lilly"}`), encoding: "gzip", name: "Lilly"},
+		{payload: []byte(`{"name": "Plain"}`), name: "Plain"},
+		{payload: []byte(`{"name": "Plain"}`), encoding: "identity", name: "Plain"},
+		{
+			payload:  gzipped(`{"nme": "Lilly"}`),
+			encoding: "gzip",
+			expect: api.Failure(422,
+				api.Error{Field: "name", Type: RequiredError.Type, Message: "Required field missing"},
+				api.Error{Field: "nme", Type: UnknownParameterError.Type, Message: "Unknown parameter sent"},
+			),
+		},
+		{payload: gzipped(`{"name": "` + strings.Repeat("a", 100) + `"}`), encoding: "gzip", limit: 50, expect: RequestBodyExceededError},
+		{payload: []byte{0x1f, 0x8b, 0x00, 0x01}, encoding: "gzip", expect: BadRequestError},
+		{payload: []byte(`{"name": "Plain"}`), encoding: "gzip", expect: BadRequestError},
+
+		// Without the header a gzip body is read as sent.
+		{payload: gzipped(`{"name": "Lilly"}`), expect: InvalidJSONError},
+		{payload: []byte(`{"name": "Lilly"}`), encoding: "br", expect: unsupported},
+	}
+
+	for i, tt := range tests {
+		r, _ := http.NewRequest("POST", "/", bytes.NewReader(tt.payload))
+		r.Header.Set("Content-Type", "application/json")
+		if tt.encoding != "" {
+			r.Header.Set("Content-Encoding", tt.encoding)
+		}
+
+		var dst schemaDest
+		v := New(schema, &opts, tt.limit)
+		sender := v.ValidRequest(r, &dst)
+		if !reflect.DeepEqual(sender, tt.expect) {
+			t.Fatalf("TestValidator_DecodeContentEncoding (%d): Expected %#v, given %#v", i, tt.expect, sender)
+		} else if dst.Name != tt.name {
+			t.Fatalf("TestValidator_DecodeContentEncoding (%d): Expected name %q, given %q", i, tt.name, dst.Name)
+		}
+	}
+
+	// Unsupported encodings fall back to BadRequest.
+	opts.UnsupportedContentEncoding = api.Error{}
+	r, _ := http.NewRequest("POST", "/", strings.NewReader(`{"name": "Lilly"}`))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Content-Encoding", "br")
+	var dst schemaDest
+	if sender := New(schema, &opts, 0).ValidRequest(r, &dst); !reflect.DeepEqual(sender, BadRequestError) {
+		t.Fatalf("TestValidator_DecodeContentEncoding: Expected %#v, given %#v", BadRequestError, sender)
+	}
+}
+
+func TestNewMultiFormat(t *testing.T) {
+	schema := gojsonschema.NewStringLoader(`{
+		"type": "object",
+		"properties": {
+			"name": {
+				"type": "string"
+			}
+		},
+		"required": ["name"],
+		"additionalProperties": false
+	}`)
+
+	type schemaDest struct {
+		Name string `json:"name"`
+	}
+
+	opts := *validatorOpts
+	opts.InvalidContentType = InvalidContentTypeError
+
+	tests := []struct {
+		contentType string
+		payload     string
+		expect      api.Sender
+		name        string
+	}{
+		{contentType: "application/json", payload: `{"name": "Lilly"}`, name: "Lilly"},
+		{contentType: "application/json; charset=utf-8", payload: `{"name": "Lilly"}`, name: "Lilly"},
+		{contentType: "application/yaml", payload: "name: Lilly\n", name: "Lilly"},
+		{contentType: "text/yaml", payload: "name: Lilly\n", name: "Lilly"},
+		{
+			contentType: "application/yaml",
+			payload:     "nme: Lilly\n",
+			expect: api.Failure(422,
+				api.Error{Field: "name", Type: RequiredError.Type, Message: "Required field missing"},
+				api.Error{Field: "nme", Type: UnknownParameterError.Type, Message: "Unknown parameter sent"},
+			),
+		},
+		{contentType: "application/yaml", payload: "", expect: RequestBodyRequiredError},
+		{contentType: "application/xml", payload: `<name>Lilly</name>`, expect: InvalidContentTypeError},
+		{contentType: "", payload: `{"name": "Lilly"}`, expect: InvalidContentTypeError},
+	}
+
+	for i, tt := range tests {
+		var dst schemaDest
+		r, _ := http.NewRequest("POST", "/", strings.NewReader(tt.payload))
+		if tt.contentType != "" {
+			r.Header.Set("Content-Type", tt.contentType)
+		}
+
+		v := NewMultiFormat(schema, &opts, 0, JSON, YAML)
+		sender := v.ValidRequest(r, &dst)
+		if !reflect.DeepEqual(sender, tt.expect) {
+			t.Fatalf("TestNewMultiFormat (%d): Expected %#v, given %#v", i, tt.expect, sender)
+		} else if dst.Name != tt.name {
+			t.Fatalf("TestNewMultiFormat (%d): Expected name %q, given %q", i, tt.name, dst.Name)
+		}
+	}
+
+	// The same document validates identically as JSON and YAML.
+	documents := []struct {
+		json string
+		yaml string
+	}{
+		{json: `{"name": "Lilly"}`, yaml: "name: Lilly\n"},
+		{json: `{"nme": "Lilly"}`, yaml: "nme: Lilly\n"},
+		{json: `{"name": 5}`, yaml: "name: 5\n"},
+		{json: `{"name": "Lilly", "tags": ["a", "b"]}`, yaml: "name: Lilly\ntags:\n  - a\n  - b\n"},
+	}
+	for i, tt := range documents {
+		var jsonDst, yamlDst schemaDest
+		v := NewMultiFormat(schema, &opts, 0, JSON, YAML)
+
+		jr, _ := http.NewRequest("POST", "/", strings.NewReader(tt.json))
+		jr.Header.Set("Content-Type", "application/json")
+		yr, _ := http.NewRequest("POST", "/", strings.NewReader(tt.yaml))
+		yr.Header.Set("Content-Type", "application/yaml")
+
+		if js, ys := v.ValidRequest(jr, &jsonDst), v.ValidRequest(yr, &yamlDst); !reflect.DeepEqual(js, ys) {
+			t.Fatalf("TestNewMultiFormat (%d): Expected JSON and YAML to match: %#v, %#v", i, js, ys)
+		} else if jsonDst != yamlDst {
+			t.Fatalf("TestNewMultiFormat (%d): Expected JSON and YAML to match: %#v, %#v", i, jsonDst, yamlDst)
+		}
+	}
+
+	// Malformed YAML returns InvalidBody, or InvalidJSON if it is not set.
+	invalidBodyOpts := opts
+	invalidBodyOpts.InvalidBody = api.Error{StatusCode: http.StatusBadRequest, Type: "invalid_body", Message: "Invalid or malformed request body"}
+	for i, tt := range []struct {
+		opts   Options
+		expect api.Sender
+	}{
+		{opts: opts, expect: InvalidJSONError},
+		{opts: invalidBodyOpts, expect: invalidBodyOpts.InvalidBody},
+	} {
+		r, _ := http.NewRequest("POST", "/", strings.NewReader("- Lilly\nname: Lilly\n"))
+		r.Header.Set("Content-Type", "application/yaml")
+		var dst schemaDest
+		if sender := NewMultiFormat(schema, &tt.opts, 0, YAML).ValidRequest(r, &dst); !reflect.DeepEqual(sender, tt.expect) {
+			t.Fatalf("TestNewMultiFormat (%d): Expected %#v, given %#v", i, tt.expect, sender)
+		}
+	}
+
+	// Without formats only JSON is accepted.
+	r, _ := http.NewRequest("POST", "/", strings.NewReader("name: Lilly\n"))
+	r.Header.Set("Content-Type", "application/yaml")
+	var dst schemaDest
+	if sender := New(schema, validatorOpts, 0).ValidRequest(r, &dst); !reflect.DeepEqual(sender, BadRequestError) {
+		t.Fatalf("TestNewMultiFormat: Expected %#v, given %#v", BadRequestError, sender)
+	}
+}
+
+func TestValidMultipart(t *testing.T) {
+	schema := gojsonschema.NewStringLoader(`{
+		"type": "object",
+		"properties": {
+			"name": {
+				"type": "string"
+			}
+		},
+		"required": ["name"],
+		"additionalProperties": false
+	}`)
+
+	type schemaDest struct {
+		Name string `json:"name"`
+	}
+
+	newRequest := func(metadata string) *http.Request {
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		if metadata != "" {
+			mw.WriteField("metadata", metadata)
+		}
+		fw, _ := mw.CreateFormFile("file", "hello.txt")
+		fw.Write([]byte("hello world"))
+		mw.Close()
+
+		r, _ := http.NewRequest("POST", "/", &buf)
+		r.Header.Set("Content-Type", mw.FormDataContentType())
+		return r
+	}
+
+	opts := *validatorOpts
+	opts.InvalidContentType = InvalidContentTypeError
+
+	tests := []struct {
+		r      *http.Request
+		expect api.Sender
+		name   string
+	}{
+		{r: newRequest(`{"name": "Lilly"}`), name: "Lilly"},
+		{
+			r: newRequest(`{"nme": "Lilly"}`),
+			expect: api.Failure(422,
+				api.Error{Field: "name", Type: RequiredError.Type, Message: "Required field missing"},
+				api.Error{Field: "nme", Type: UnknownParameterError.Type, Message: "Unknown parameter sent"},
+			),
+		},
+		{r: newRequest(`{"name": `), expect: InvalidJSONError},
+		{r: newRequest(""), expect: RequestBodyRequiredError},
+	}
+
+	for i, tt := range tests {
+		var dst schemaDest
+		v := New(schema, &opts, 0)
+		form, sender := v.ValidMultipart(tt.r, "metadata", &dst)
+		if !reflect.DeepEqual(sender, tt.expect) {
+			t.Fatalf("TestValidMultipart (%d): Expected %#v, given %#v", i, tt.expect, sender)
+		} else if dst.Name != tt.name {
+			t.Fatalf("TestValidMultipart (%d): Expected name %q, given %q", i, tt.name, dst.Name)
+		} else if tt.expect != nil {
+			if form != nil {
+				t.Fatalf("TestValidMultipart (%d): Expected nil form", i)
+			}
+			continue
+		}
+
+		if len(form.File["file"]) != 1 {
+			t.Fatalf("TestValidMultipart (%d): Expected file part", i)
+		}
+		f, _ := form.File["file"][0].Open()
+		b, _ := ioutil.ReadAll(f)
+		f.Close()
+		form.RemoveAll()
+		if string(b) != "hello world" {
+			t.Fatalf("TestValidMultipart (%d): Unexpected file contents: %s", i, b)
+		}
+	}
+
+	// Not multipart
+	r, _ := http.NewRequest("POST", "/", strings.NewReader(`{"name": "Lilly"}`))
+	r.Header.Set("Content-Type", "application/json")
+	var dst schemaDest
+	if _, sender := New(schema, &opts, 0).ValidMultipart(r, "metadata", &dst); !reflect.DeepEqual(sender, InvalidContentTypeError) {
+		t.Fatalf("TestValidMultipart: Expected %#v, given %#v", InvalidContentTypeError, sender)
+	}
+
+	// Uploads larger than MaxMultipartSize are rejected.
+	maxOpts := opts
+	maxOpts.MaxMultipartSize = 64
+	if form, sender := New(schema, &maxOpts, 0).ValidMultipart(newRequest(`{"name": "Lilly"}`), "metadata", &dst); !reflect.DeepEqual(sender, RequestBodyExceededError) {
+		t.Fatalf("TestValidMultipart: Expected %#v, given %#v", RequestBodyExceededError, sender)
+	} else if form != nil {
+		t.Fatal("TestValidMultipart: Expected nil form")
+	}
+}
+
+func TestValidQuery(t *testing.T) {
+	schema := gojsonschema.NewStringLoader(`{
+		"type": "object",
+		"properties": {
+			"q": {
+				"type": "string"
+			},
+			"age": {
+				"type": "integer"
+			},
+			"active": {
+				"type": "boolean"
+			},
+			"sort": {
+				"type": "string",
+				"enum": ["asc", "desc"]
+			},
+			"id": {
+				"type": "array",
+				"items": {
+					"type": "integer"
+				}
+			}
+		},
+		"required": ["q"],
+		"additionalProperties": false
+	}`)
+
+	type queryDest struct {
+		Q      string `json:"q"`
+		Age    int    `json:"age"`
+		Active bool   `json:"active"`
+		Sort   string `json:"sort"`
+		ID     []int  `json:"id"`
+	}
+
+	tests := []struct {
+		query  string
+		expect api.Sender
+		dst    queryDest
+	}{
+		{
+			query: "q=123&age=30&active=1&sort=asc&id=1&id=2",
+			dst:   queryDest{Q: "123", Age: 30, Active: true, Sort: "asc", ID: []int{1, 2}},
+		},
+		{query: "q=shoes&id=3", dst: queryDest{Q: "shoes", ID: []int{3}}},
+		{
+			query: "age=30",
+			expect: api.Failure(422,
+				api.Error{Field: "q", Type: RequiredError.Type, Message: "Required field missing"},
+			),
+		},
+		{
+			query: "q=shoes&sort=random",
+			expect: api.Failure(422,
+				api.Error{Field: "sort", Type: InvalidValueError.Type, Message: "The provided value is invalid"},
+			),
+		},
+		{
+			query: "q=shoes&age=thirty",
+			expect: api.Failure(422,
+				api.Error{Field: "age", Type: InvalidTypeError.Type, Message: InvalidTypeError.Message},
+			),
+		},
+		{
+			query: "q=shoes&page=2",
+			expect: api.Failure(422,
+				api.Error{Field: "page", Type: UnknownParameterError.Type, Message: "Unknown parameter sent"},
+			),
+		},
+	}
+
+	for i, tt := range tests {
+		var dst queryDest
+		r, _ := http.NewRequest("GET", "/?"+tt.query, nil)
+		v := New(schema, validatorOpts, 0)
+		sender := v.ValidQuery(r, &dst)
+		if !reflect.DeepEqual(sender, tt.expect) {
+			t.Fatalf("TestValidQuery (%d): Expected %#v, given %#v", i, tt.expect, sender)
+		} else if tt.expect == nil && !reflect.DeepEqual(dst, tt.dst) {
+			t.Fatalf("TestValidQuery (%d): Expected %#v, given %#v", i, tt.dst, dst)
+		}
+	}
+}
+
+// countingLoader counts the times the schema is loaded.
+type countingLoader struct {
+	gojsonschema.JSONLoader
+	loads int
+}
+
+func (l *countingLoader) LoadJSON() (interface{}, error) {
+	l.loads++
+	return l.JSONLoader.LoadJSON()
+}
+
+func TestValidQuery_LoadsSchemaOnce(t *testing.T) {
+	schema := &countingLoader{JSONLoader: gojsonschema.NewStringLoader(`{
+		"type": "object",
+		"properties": {
+			"age": {
+				"type": "integer"
+			}
+		}
+	}`)}
+
+	v := New(schema, validatorOpts, 0)
+	if err := v.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	schema.loads = 0
+
+	for i := 0; i < 3; i++ {
+		var dst struct {
+			Age int `json:"age"`
+		}
+		r := httptest.NewRequest("GET", "/?age=30", nil)
+		if sender := v.ValidQuery(r, &dst); sender != nil {
+			t.Fatalf("TestValidQuery_LoadsSchemaOnce (%d): Expected nil, given %#v", i, sender)
+		} else if dst.Age != 30 {
+			t.Fatalf("TestValidQuery_LoadsSchemaOnce (%d): Expected age 30, given %d", i, dst.Age)
+		}
+	}
+
+	if schema.loads != 1 {
+		t.Fatalf("TestValidQuery_LoadsSchemaOnce: Expected schema to be loaded once, given %d", schema.loads)
+	}
+}
+
+func TestValidator_RequireContentType(t *testing.T) {
+	schema := gojsonschema.NewStringLoader(`{
+		"type": "object",
+		"properties": {
+			"name": {
+				"type": "string"
+			}
+		},
+		"required": ["name"]
+	}`)
+
+	type schemaDest struct {
+		Name string `json:"name"`
+	}
+
+	opts := *validatorOpts
+	opts.InvalidContentType = InvalidContentTypeError
+	opts.RequireContentType = []string{"application/json"}
+
+	tests := []struct {
+		contentType string
+		expect      api.Sender
+		name        string
+	}{
+		{contentType: "application/json", name: "Lilly"},
+		{contentType: "Application/JSON; charset=utf-8", name: "Lilly"},
+		{contentType: "", expect: InvalidContentTypeError},
+		{contentType: "text/plain", expect: InvalidContentTypeError},
+	}
+
+	for i, tt := range tests {
+		var dst schemaDest
+		r, _ := http.NewRequest("POST", "/", strings.NewReader(`{"name": "Lilly"}`))
+		if tt.contentType != "" {
+			r.Header.Set("Content-Type", tt.contentType)
+		}
+
+		sender := New(schema, &opts, 0).ValidRequest(r, &dst)
+		if !reflect.DeepEqual(sender, tt.expect) {
+			t.Fatalf("TestValidator_RequireContentType (%d): Expected %#v, given %#v", i, tt.expect, sender)
+		} else if dst.Name != tt.name {
+			t.Fatalf("TestValidator_RequireContentType (%d): Expected name %q, given %q", i, tt.name, dst.Name)
+		}
+	}
+
+	// A Format is still required for a Content-Type in the list.
+	opts.RequireContentType = []string{"application/json", "text/plain"}
+	r, _ := http.NewRequest("POST", "/", strings.NewReader(`{"name": "Lilly"}`))
+	r.Header.Set("Content-Type", "text/plain")
+	var dst schemaDest
+	if sender := New(schema, &opts, 0).ValidRequest(r, &dst); !reflect.DeepEqual(sender, InvalidContentTypeError) {
+		t.Fatalf("TestValidator_RequireContentType: Expected %#v, given %#v", InvalidContentTypeError, sender)
+	}
+}
+
+func TestValidBytes(t *testing.T) {
+	schema := gojsonschema.NewStringLoader(`{
+		"type": "object",
+		"properties": {
+			"name": {
+				"type": "string"
+			}
+		},
+		"required": ["name"]
+	}`)
+
+	type schemaDest struct {
+		Name string `json:"name"`
+	}
+
+	tests := []struct {
+		payload string
+		expect  api.Sender
+		name    string
+	}{
+		{payload: `{"name": "Lilly"}`, name: "Lilly"},
+		{payload: `{"name": 5}`, expect: api.Failure(422, api.Error{Field: "name", Type: InvalidTypeError.Type, Message: InvalidTypeError.Message})},
+		{payload: `{"name": `, expect: InvalidJSONError},
+		{payload: ``, expect: RequestBodyRequiredError},
+	}
+
+	for i, tt := range tests {
+		var dst schemaDest
+		sender := New(schema, validatorOpts, 0).ValidBytes([]byte(tt.payload), &dst)
+		if !reflect.DeepEqual(sender, tt.expect) {
+			t.Fatalf("TestValidBytes (%d): Expected %#v, given %#v", i, tt.expect, sender)
+		} else if dst.Name != tt.name {
+			t.Fatalf("TestValidBytes (%d): Expected name %q, given %q", i, tt.name, dst.Name)
+		}
+	}
+}
+
+func TestValidRequest_BufferedBody(t *testing.T) {
+	schema := gojsonschema.NewStringLoader(`{
+		"type": "object",
+		"properties": {
+			"name": {
+				"type": "string"
+			}
+		},
+		"required": ["name"]
+	}`)
+
+	type schemaDest struct {
+		Name string `json:"name"`
+	}
+
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{"name": "Lilly"}`))
+	r.Header.Set("Content-Type", "application/json")
+	if _, err := kumi.BufferBody(r, 1024); err != nil {
+		t.Fatalf("TestValidRequest_BufferedBody: Unexpected error: %s", err)
+	}
+
+	// Another consumer reads the body before the validator.
+	if b, _ := ioutil.ReadAll(r.Body); string(b) != `{"name": "Lilly"}` {
+		t.Fatalf("TestValidRequest_BufferedBody: Unexpected body: %q", b)
+	}
+
+	var dst schemaDest
+	if sender := New(schema, validatorOpts, 0).ValidRequest(r, &dst); sender != nil {
+		t.Fatalf("TestValidRequest_BufferedBody: Expected nil, given %#v", sender)
+	} else if dst.Name != "Lilly" {
+		t.Fatalf("TestValidRequest_BufferedBody: Expected name Lilly, given %q", dst.Name)
+	}
+}
+
+func TestValid_BufferedBody(t *testing.T) {
+	schema := gojsonschema.NewStringLoader(`{
+		"type": "object",
+		"properties": {
+			"name": {
+				"type": "string"
+			}
+		},
+		"required": ["name"]
+	}`)
+
+	type schemaDest struct {
+		Name string `json:"name"`
+	}
+
+	var dst schemaDest
+	v := New(schema, validatorOpts, 0)
+	k := kumi.New(router.NewHTTPRouter())
+	k.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// A middleware buffers and reads the body before the handler.
+			kumi.BufferBody(r, 1024)
+			ioutil.ReadAll(r.Body)
+			next.ServeHTTP(w, r)
+		})
+	})
+	k.Post("/", func(w http.ResponseWriter, r *http.Request) {
+		if sender := v.Valid(r.Body, &dst); sender != nil {
+			sender.Send(w)
+		}
+	})
+
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{"name": "Lilly"}`))
+	w := httptest.NewRecorder()
+	k.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("TestValid_BufferedBody: Expected status 200, given %d: %s", w.Code, w.Body.String())
+	} else if dst.Name != "Lilly" {
+		t.Fatalf("TestValid_BufferedBody: Expected name Lilly, given %q", dst.Name)
+	}
+}
+
+func TestValidator_UseNumber(t *testing.T) {
+	schema := gojsonschema.NewStringLoader(`{
+		"type": "object",
+		"properties": {
+			"id": {
+				"type": "integer"
+			}
+		},
+		"required": ["id"]
+	}`)
+
+	tests := []struct {
+		useNumber bool
+		expect    interface{}
+	}{
+		{expect: float64(9007199254740992)}, // 2^53 + 1 rounds to 2^53
+		{useNumber: true, expect: json.Number("9007199254740993")},
+	}
+
+	for i, tt := range tests {
+		opts := *validatorOpts
+		opts.UseNumber = tt.useNumber
+
+		var dst map[string]interface{}
+		if sender := New(schema, &opts, 0).ValidBytes([]byte(`{"id": 9007199254740993}`), &dst); sender != nil {
+			t.Fatalf("TestValidator_UseNumber (%d): Expected nil, given %#v", i, sender)
+		} else if !reflect.DeepEqual(dst["id"], tt.expect) {
+			t.Fatalf("TestValidator_UseNumber (%d): Expected %#v, given %#v", i, tt.expect, dst["id"])
+		}
+	}
+
+	// Typed fields are unaffected.
+	var dst struct {
+		ID int64 `json:"id"`
+	}
+	opts := *validatorOpts
+	opts.UseNumber = true
+	if sender := New(schema, &opts, 0).ValidBytes([]byte(`{"id": 9007199254740993}`), &dst); sender != nil {
+		t.Fatalf("TestValidator_UseNumber: Expected nil, given %#v", sender)
+	} else if dst.ID != 9007199254740993 {
+		t.Fatalf("TestValidator_UseNumber: Expected 9007199254740993, given %d", dst.ID)
+	}
+}
+
+func TestRegistry_Compile(t *testing.T) {
+	valid := gojsonschema.NewStringLoader(`{"type": "object", "properties": {"name": {"type": "string"}}}`)
+	invalid := gojsonschema.NewStringLoader(`{"type": "object", "properties": {"name": {"type": "strnig"}}}`)
+
+	registry := NewRegistry()
+	user := registry.Register("user", New(valid, validatorOpts, 0))
+	if err := registry.Compile(); err != nil {
+		t.Fatalf("TestRegistry_Compile: Unexpected error: %s", err)
+	}
+
+	// Compiled schemas are used for validation.
+	var dst map[string]interface{}
+	if sender := user.ValidBytes([]byte(`{"name": 5}`), &dst); sender == nil {
+		t.Fatal("TestRegistry_Compile: Expected invalid document to fail with compiled schema")
+	}
+
+	registry.Register("account", New(invalid, validatorOpts, 0))
+	if err := registry.Compile(); err == nil || !strings.Contains(err.Error(), `"account"`) {
+		t.Fatalf("TestRegistry_Compile: Expected error naming the account schema, given %v", err)
+	}
+}
+
+func TestValidator_MaxErrors(t *testing.T) {
+	schema := gojsonschema.NewStringLoader(`{
+		"type": "object",
+		"properties": {
+			"a": {"type": "string"},
+			"b": {"type": "string"},
+			"c": {"type": "string"},
+			"d": {"type": "string"},
+			"e": {"type": "string"}
+		},
+		"required": ["a", "b", "c", "d", "e"]
+	}`)
+
+	opts := *validatorOpts
+	opts.MaxErrors = 3
+
+	var dst map[string]interface{}
+	sender := New(schema, &opts, 0).Valid(strings.NewReader(`{}`), &dst)
+
+	expect := api.Failure(422,
+		api.Error{Field: "a", Type: RequiredError.Type, Message: "Required field missing"},
+		api.Error{Field: "b", Type: RequiredError.Type, Message: "Required field missing"},
+		api.Error{Field: "c", Type: RequiredError.Type, Message: "Required field missing"},
+		api.Error{Type: "errors_omitted", Message: "2 more errors omitted"},
+	)
+	if !reflect.DeepEqual(sender, expect) {
+		t.Fatalf("TestValidator_MaxErrors: Expected %#v, given %#v", expect, sender)
+	}
+
+	// Not truncated when at the limit.
+	sender = New(schema, &opts, 0).Valid(strings.NewReader(`{"a": "a", "b": "b"}`), &dst)
+	if e := sender.(*api.ErrorResponse).Errors; len(e) != 3 {
+		t.Fatalf("TestValidator_MaxErrors: Expected 3 errors, given %#v", e)
+	}
+}
+
+func TestValidator_KeepCompositeErrors(t *testing.T) {
+	schema := gojsonschema.NewStringLoader(`{
+		"type": "object",
+		"oneOf": [{
+			"properties": {
+				"name": {
+					"type": "string"
+				}
+			},
+			"required": ["name"]
+		}, {
+			"properties": {
+				"id": {
+					"type": "integer"
+				}
+			},
+			"required": ["id"]
+		}]
+	}`)
+
+	tests := []struct {
+		keep    bool
+		swapper Swapper
+		expect  api.Sender
+	}{
+		{
+			expect: api.Failure(422,
+				api.Error{Field: "name", Type: RequiredError.Type, Message: "Required field missing"},
+			),
+		},
+		{
+			keep: true,
+			expect: api.Failure(422,
+				api.Error{Type: InvalidParametersError.Type, Message: "One or more parameters is invalid"},
+				api.Error{Field: "name", Type: RequiredError.Type, Message: "Required field missing"},
+			),
+		},
+		{
+			keep:    true,
+			swapper: Swap,
+			expect: api.Failure(422,
+				api.Error{Type: InvalidParametersError.Type, Message: "One or more parameters is invalid"},
+				api.Error{Field: "name", Type: RequiredError.Type, Message: "Required field missing"},
+			),
+		},
+	}
+
+	for i, tt := range tests {
+		opts := *validatorOpts
+		opts.KeepCompositeErrors = tt.keep
+		opts.Swapper = tt.swapper
+
+		var dst map[string]interface{}
+		sender := New(schema, &opts, 0).Valid(strings.NewReader(`{}`), &dst)
+		if !reflect.DeepEqual(sender, tt.expect) {
+			t.Fatalf("TestValidator_KeepCompositeErrors (%d): Expected %#v, given %#v", i, tt.expect, sender)
+		}
+	}
+}
+
+// Ensures New defaults the Swapper to Swap.
+func TestValidator_DefaultSwapper(t *testing.T) {
+	opts := *validatorOpts
+	opts.Swapper = nil
+	New(gojsonschema.NewStringLoader(`{}`), &opts, 0)
+	if opts.Swapper == nil {
+		t.Fatal("TestValidator_DefaultSwapper: Expected New to set the Swapper")
+	}
+}
+
+func TestValidator_SwapperWithContext(t *testing.T) {
+	schema := gojsonschema.NewStringLoader(`{
+		"type": "object",
+		"properties": {
+			"name": {
+				"type": "string"
+			}
+		},
+		"required": ["name"]
+	}`)
+
+	messages := map[string]map[string]string{
+		"es": {RequiredError.Type: "Falta un campo obligatorio"},
+	}
+
+	opts := *validatorOpts
+	opts.SwapperWithContext = func(r *http.Request, errors []gojsonschema.ResultError, rules Rules) []api.Error {
+		e := Swap(errors, rules)
+		if m, ok := messages[r.Header.Get("Accept-Language")]; ok {
+			for i := range e {
+				if msg, ok := m[e[i].Type]; ok {
+					e[i].Message = msg
+				}
+			}
+		}
+		return e
+	}
+
+	tests := []struct {
+		lang   string
+		expect api.Sender
+	}{
+		{lang: "es", expect: api.Failure(422, api.Error{Field: "name", Type: RequiredError.Type, Message: "Falta un campo obligatorio"})},
+		{lang: "en", expect: api.Failure(422, api.Error{Field: "name", Type: RequiredError.Type, Message: "Required field missing"})},
+	}
+
+	for i, tt := range tests {
+		r, _ := http.NewRequest("POST", "/", strings.NewReader(`{}`))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("Accept-Language", tt.lang)
+
+		var dst map[string]interface{}
+		if sender := New(schema, &opts, 0).ValidRequest(r, &dst); !reflect.DeepEqual(sender, tt.expect) {
+			t.Fatalf("TestValidator_SwapperWithContext (%d): Expected %#v, given %#v", i, tt.expect, sender)
+		}
+	}
+
+	// Valid has no request, so the default Swap is used.
+	var dst map[string]interface{}
+	expect := api.Failure(422, api.Error{Field: "name", Type: RequiredError.Type, Message: "Required field missing"})
+	if sender := New(schema, &opts, 0).Valid(strings.NewReader(`{}`), &dst); !reflect.DeepEqual(sender, expect) {
+		t.Fatalf("TestValidator_SwapperWithContext: Expected %#v, given %#v", expect, sender)
+	}
+}
+
+// Ensures a custom Swapper is used by Valid and ValidRequest.
+func TestValidator_Swapper(t *testing.T) {
+	schema := gojsonschema.NewStringLoader(`{
+		"type": "object",
+		"properties": {
+			"name": {
+				"type": "string"
+			}
+		},
+		"required": ["name"]
+	}`)
+
+	opts := *validatorOpts
+	opts.Swapper = func(errors []gojsonschema.ResultError, rules Rules) []api.Error {
+		e := Swap(errors, rules)
+		for i := range e {
+			e[i].Message = strings.ToUpper(e[i].Message)
+		}
+		return e
+	}
+	v := New(schema, &opts, 0)
+	expect := api.Failure(422, api.Error{Field: "name", Type: RequiredError.Type, Message: "REQUIRED FIELD MISSING"})
+
+	var dst map[string]interface{}
+	if sender := v.Valid(strings.NewReader(`{}`), &dst); !reflect.DeepEqual(sender, expect) {
+		t.Fatalf("TestValidator_Swapper: Expected %#v, given %#v", expect, sender)
+	}
+
+	r, _ := http.NewRequest("POST", "/", strings.NewReader(`{}`))
+	r.Header.Set("Content-Type", "application/json")
+	if sender := v.ValidRequest(r, &dst); !reflect.DeepEqual(sender, expect) {
+		t.Fatalf("TestValidator_Swapper: Expected %#v, given %#v", expect, sender)
+	}
+}
+
 // func TestDependency(t *testing.T) {
 // 	v := New(gojsonschema.NewStringLoader(`{
 //                 "type":"number",
@@ -0,0 +1,94 @@
+package kumi
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// ErrBodyTooLarge is returned by BufferBody when the request body exceeds
+// the limit.
+var ErrBodyTooLarge = errors.New("body: request body too large")
+
+// BufferBody reads the request body once, up to limit bytes, and stores it
+// on the request context. r.Body is replaced with a fresh reader over the
+// buffered bytes on every call so later readers, including the validator,
+// see the full body. If the body has already been buffered the stored
+// bytes are returned without reading again.
+//
+// If the body exceeds limit, ErrBodyTooLarge is returned and r.Body is
+// restored so it can still be read in full.
+func BufferBody(r *http.Request, limit int64) ([]byte, error) {
+	if b, ok := BufferedBody(r); ok {
+		r.Body = newBufferedBody(b)
+		return b, nil
+	}
+	if r.Body == nil || r.Body == http.NoBody {
+		r.Body = newBufferedBody(nil)
+		setBufferedBody(r, nil)
+		return nil, nil
+	}
+
+	b, err := ioutil.ReadAll(io.LimitReader(r.Body, limit+1))
+	if err != nil {
+		r.Body.Close()
+		return nil, err
+	} else if int64(len(b)) > limit {
+		r.Body = &restoredBody{Reader: io.MultiReader(bytes.NewReader(b), r.Body), Closer: r.Body}
+		return nil, ErrBodyTooLarge
+	}
+	r.Body.Close()
+
+	r.Body = newBufferedBody(b)
+	setBufferedBody(r, b)
+	return b, nil
+}
+
+// restoredBody is a partially read request body with the read bytes put
+// back in front of the rest.
+type restoredBody struct {
+	io.Reader
+	io.Closer
+}
+
+// BufferedBody returns the request body stored by BufferBody, if any.
+func BufferedBody(r *http.Request) ([]byte, bool) {
+	if rc, ok := r.Context().Value(contextKey).(*requestContext); ok && rc.bodyBuffered {
+		return rc.body, true
+	}
+	if bb, ok := r.Body.(*bufferedBody); ok {
+		return bb.b, true
+	}
+	return nil, false
+}
+
+// setBufferedBody stores b on kumi's RequestContext, if there is one.
+func setBufferedBody(r *http.Request, b []byte) {
+	if rc, ok := r.Context().Value(contextKey).(*requestContext); ok {
+		rc.body = b
+		rc.bodyBuffered = true
+	}
+}
+
+// bufferedBody is a request body backed by buffered bytes.
+type bufferedBody struct {
+	*bytes.Reader
+	b []byte
+}
+
+func newBufferedBody(b []byte) *bufferedBody {
+	return &bufferedBody{Reader: bytes.NewReader(b), b: b}
+}
+
+// Close is a no-op.
+func (bb *bufferedBody) Close() error {
+	return nil
+}
+
+// BufferedBody returns the buffered bytes. It allows packages that do not
+// import kumi, such as the validator, to detect a buffered body.
+func (bb *bufferedBody) BufferedBody() []byte {
+	return bb.b
+}
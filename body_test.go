@@ -0,0 +1,69 @@
+package kumi_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cristiangraz/kumi"
+)
+
+func TestBufferBody(t *testing.T) {
+	var signed, logged, handled string
+	verify := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, err := kumi.BufferBody(r, 1024)
+			if err != nil {
+				t.Fatalf("TestBufferBody: Unexpected error: %s", err)
+			}
+			signed = string(b)
+
+			// Consume r.Body directly as a logger would.
+			b, _ = ioutil.ReadAll(r.Body)
+			logged = string(b)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	k := kumi.New(&Router{})
+	k.Use(verify)
+	k.Post("/", func(w http.ResponseWriter, r *http.Request) {
+		b, err := kumi.BufferBody(r, 1024)
+		if err != nil {
+			t.Fatalf("TestBufferBody: Unexpected error: %s", err)
+		} else if buffered, ok := kumi.BufferedBody(r); !ok || string(buffered) != string(b) {
+			t.Fatalf("TestBufferBody: Expected buffered body %q, given %q", b, buffered)
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		handled = string(body)
+	})
+
+	r, _ := http.NewRequest("POST", "/", strings.NewReader(`{"name":"kumi"}`))
+	w := httptest.NewRecorder()
+	k.ServeHTTP(w, r)
+
+	for _, given := range []string{signed, logged, handled} {
+		if given != `{"name":"kumi"}` {
+			t.Fatalf("TestBufferBody: Expected body to be readable by every consumer, given %q", given)
+		}
+	}
+}
+
+func TestBufferBody_Limit(t *testing.T) {
+	r, _ := http.NewRequest("POST", "/", strings.NewReader("12345"))
+	if _, err := kumi.BufferBody(r, 4); err != kumi.ErrBodyTooLarge {
+		t.Fatalf("TestBufferBody_Limit: Expected %v, given %v", kumi.ErrBodyTooLarge, err)
+	} else if b, _ := ioutil.ReadAll(r.Body); string(b) != "12345" {
+		t.Fatalf("TestBufferBody_Limit: Expected body to be restored, given %q", b)
+	} else if _, ok := kumi.BufferedBody(r); ok {
+		t.Fatal("TestBufferBody_Limit: Expected body not to be buffered")
+	}
+
+	r, _ = http.NewRequest("POST", "/", strings.NewReader("1234"))
+	if b, err := kumi.BufferBody(r, 4); err != nil || string(b) != "1234" {
+		t.Fatalf("TestBufferBody_Limit: Expected 1234, given %q (%v)", b, err)
+	}
+}
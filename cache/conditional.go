@@ -0,0 +1,25 @@
+package cache
+
+import (
+	"net/http"
+	"time"
+)
+
+// CheckNotModified reports whether the request's If-Modified-Since header
+// shows the client already has the representation last modified at
+// lastModified. HTTP dates have no sub-second component, so lastModified
+// is truncated to the second before comparing. Only GET and HEAD requests
+// without an If-None-Match header are considered.
+func CheckNotModified(r *http.Request, lastModified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	} else if lastModified.IsZero() || r.Header.Get("If-None-Match") != "" {
+		return false
+	}
+
+	ims, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !lastModified.Truncate(time.Second).After(ims)
+}
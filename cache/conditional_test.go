@@ -0,0 +1,41 @@
+package cache
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCheckNotModified(t *testing.T) {
+	lastModified := time.Date(2017, 1, 2, 15, 4, 5, 500, time.UTC)
+
+	tests := []struct {
+		method          string
+		ifModifiedSince string
+		ifNoneMatch     string
+		expected        bool
+	}{
+		{method: "GET", expected: false},
+		{method: "GET", ifModifiedSince: "Mon, 02 Jan 2017 15:04:05 GMT", expected: true},  // equal
+		{method: "GET", ifModifiedSince: "Mon, 02 Jan 2017 15:04:04 GMT", expected: false}, // older
+		{method: "GET", ifModifiedSince: "Mon, 02 Jan 2017 15:04:06 GMT", expected: true},  // newer
+		{method: "HEAD", ifModifiedSince: "Mon, 02 Jan 2017 15:04:05 GMT", expected: true},
+		{method: "POST", ifModifiedSince: "Mon, 02 Jan 2017 15:04:05 GMT", expected: false},
+		{method: "GET", ifModifiedSince: "invalid", expected: false},
+		{method: "GET", ifModifiedSince: "Mon, 02 Jan 2017 15:04:05 GMT", ifNoneMatch: `"abc"`, expected: false},
+	}
+
+	for i, tt := range tests {
+		r, _ := http.NewRequest(tt.method, "/", nil)
+		if tt.ifModifiedSince != "" {
+			r.Header.Set("If-Modified-Since", tt.ifModifiedSince)
+		}
+		if tt.ifNoneMatch != "" {
+			r.Header.Set("If-None-Match", tt.ifNoneMatch)
+		}
+
+		if given := CheckNotModified(r, lastModified); given != tt.expected {
+			t.Errorf("TestCheckNotModified (%d): Expected %v, given %v", i, tt.expected, given)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package cache
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+)
+
+// ComputeETag returns an ETag for body using the hex encoded SHA-1 of
+// its contents. Strong ETags are formatted as "<hex>" and weak ETags
+// as W/"<hex>".
+func ComputeETag(body []byte, weak bool) string {
+	sum := sha1.Sum(body)
+	b := make([]byte, 0, 4+hex.EncodedLen(len(sum)))
+	if weak {
+		b = append(b, "W/"...)
+	}
+	b = append(b, '"')
+	b = append(b, hex.EncodeToString(sum[:])...)
+	b = append(b, '"')
+	return string(b)
+}
@@ -0,0 +1,17 @@
+package cache
+
+import "testing"
+
+func TestComputeETag(t *testing.T) {
+	body := []byte("hello world")
+
+	if strong := ComputeETag(body, false); strong != `"2aae6c35c94fcfb415dbe95f408b9ce91ee846ed"` {
+		t.Fatalf("unexpected strong etag: %s", strong)
+	} else if weak := ComputeETag(body, true); weak != `W/"2aae6c35c94fcfb415dbe95f408b9ce91ee846ed"` {
+		t.Fatalf("unexpected weak etag: %s", weak)
+	} else if ComputeETag(body, false) != ComputeETag([]byte("hello world"), false) {
+		t.Fatal("expected identical input to produce identical etags")
+	} else if ComputeETag(body, false) == ComputeETag([]byte("hello world!"), false) {
+		t.Fatal("expected different input to produce different etags")
+	}
+}
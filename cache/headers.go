@@ -2,7 +2,6 @@ package cache
 
 import (
 	"net/http"
-	"regexp"
 	"strconv"
 	"sync"
 )
@@ -175,6 +174,16 @@ func (h *Headers) SetSharedMaxAge(age int64) *Headers {
 	return h
 }
 
+// MaxAge returns the max-age directive and whether it is set.
+func (h *Headers) MaxAge() (int64, bool) {
+	return h.maxAge.Int64, h.maxAge.Valid
+}
+
+// SharedMaxAge returns the s-maxage directive and whether it is set.
+func (h *Headers) SharedMaxAge() (int64, bool) {
+	return h.sharedMaxAge.Int64, h.sharedMaxAge.Valid
+}
+
 // convenience byte slices
 var (
 	equalSign = []byte("=")
@@ -186,6 +195,7 @@ func (h *Headers) String() string {
 	// Because there is a finite number of fields, the fields are appended in
 	// alphabetical order so we don't need a sorting algorithm.
 	// The fields are appended to a byte buffer to minimize allocations.
+	h.b = h.b[:0]
 	if h.maxAge.Valid {
 		if len(h.b) > 0 {
 			h.b = append(h.b, separate...)
@@ -249,6 +259,55 @@ func (h *Headers) String() string {
 	return string(h.b)
 }
 
+// WriteTo sets the Cache-Control header. If there are no directives the
+// header is left untouched.
+func (h *Headers) WriteTo(header http.Header) {
+	if h.IsEmpty() {
+		return
+	}
+	header.Set("Cache-Control", h.String())
+}
+
+// Equal reports whether h and other have the same directives. Unlike
+// reflect.DeepEqual, the internal buffer is ignored.
+func (h *Headers) Equal(other *Headers) bool {
+	return len(h.Diff(other)) == 0
+}
+
+// Diff returns the names of the directives that differ between h and
+// other, in the same order String writes them.
+func (h *Headers) Diff(other *Headers) []string {
+	var diff []string
+	if h.maxAge != other.maxAge {
+		diff = append(diff, string(maxAge))
+	}
+	if h.mustRevalidate != other.mustRevalidate {
+		diff = append(diff, string(mustRevalidate))
+	}
+	if h.noCache != other.noCache {
+		diff = append(diff, string(noCache))
+	}
+	if h.noStore != other.noStore {
+		diff = append(diff, string(noStore))
+	}
+	if h.noTransform != other.noTransform {
+		diff = append(diff, string(noTransform))
+	}
+	if h.proxyRevalidate != other.proxyRevalidate {
+		diff = append(diff, string(proxyRevalidate))
+	}
+	if h.public != other.public {
+		diff = append(diff, string(public))
+	}
+	if h.private != other.private {
+		diff = append(diff, string(private))
+	}
+	if h.sharedMaxAge != other.sharedMaxAge {
+		diff = append(diff, string(sharedMaxAge))
+	}
+	return diff
+}
+
 func appendByteSlices(bb []byte, b ...[]byte) []byte {
 	for _, buf := range b {
 		bb = append(bb, buf...)
@@ -279,27 +338,50 @@ func (h *Headers) SensibleDefaults(header http.Header, status int) string {
 	return h.String()
 }
 
-var rxCacheControlHeader = regexp.MustCompile(`([a-zA-Z][a-zA-Z_-]*)\s*(?:=(?:"([^"]*)"|([^ \t",;]*)))?`)
-
-// Parse parses a cache-control header
+// Parse parses a cache-control header. Directive names start with a
+// letter followed by letters, underscores, or hyphens and may have a
+// quoted or unquoted value. Unrecognized directives are ignored.
 func (h *Headers) Parse(cc string) {
-	if cc == "" {
-		return
-	}
+	for i := 0; i < len(cc); {
+		if !isDirectiveStart(cc[i]) {
+			i++
+			continue
+		}
 
-	matches := rxCacheControlHeader.FindAllStringSubmatch(cc, -1)
-	for _, v := range matches {
-		switch v[1] {
+		start := i
+		for i++; i < len(cc) && isDirectiveChar(cc[i]); i++ {
+		}
+		name := cc[start:i]
+
+		// Optional value: whitespace, '=', then a quoted or unquoted value.
+		// Only unquoted values are used by the recognized directives.
+		var value string
+		j := i
+		for j < len(cc) && isSpace(cc[j]) {
+			j++
+		}
+		if j < len(cc) && cc[j] == '=' {
+			i = j + 1
+			if end := quotedValueEnd(cc, i); end > 0 {
+				i = end
+			} else {
+				for start = i; i < len(cc) && !isValueDelim(cc[i]); i++ {
+				}
+				value = cc[start:i]
+			}
+		}
+
+		switch name {
 		case "public":
 			h.SetPublic()
 		case "private":
 			h.SetPrivate()
 		case "max-age":
-			i, _ := strconv.ParseInt(v[3], 10, 64)
-			h.maxAge = nullInt64{Int64: i, Valid: true}
+			n, _ := strconv.ParseInt(value, 10, 64)
+			h.maxAge = nullInt64{Int64: n, Valid: true}
 		case "s-maxage":
-			i, _ := strconv.ParseInt(v[3], 10, 64)
-			h.sharedMaxAge = nullInt64{Int64: i, Valid: true}
+			n, _ := strconv.ParseInt(value, 10, 64)
+			h.sharedMaxAge = nullInt64{Int64: n, Valid: true}
 		case "no-cache":
 			h.noCache = true
 		case "no-store":
@@ -311,3 +393,33 @@ func (h *Headers) Parse(cc string) {
 		}
 	}
 }
+
+// quotedValueEnd returns the index after the closing quote of a quoted
+// value starting at i, or 0 if there is no quoted value at i.
+func quotedValueEnd(cc string, i int) int {
+	if i >= len(cc) || cc[i] != '"' {
+		return 0
+	}
+	for j := i + 1; j < len(cc); j++ {
+		if cc[j] == '"' {
+			return j + 1
+		}
+	}
+	return 0
+}
+
+func isDirectiveStart(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDirectiveChar(c byte) bool {
+	return isDirectiveStart(c) || c == '_' || c == '-'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\f' || c == '\r'
+}
+
+func isValueDelim(c byte) bool {
+	return c == ' ' || c == '\t' || c == '"' || c == ',' || c == ';'
+}
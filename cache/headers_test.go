@@ -20,6 +20,17 @@ func BenchmarkDefaults(b *testing.B) {
 	}
 }
 
+func BenchmarkParse(b *testing.B) {
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		h := New()
+		h.Parse(`private, no-cache="Set-Cookie", no-transform, max-age=30, s-maxage=10`)
+		Release(h)
+	}
+}
+
 func TestParseCacheControl(t *testing.T) {
 	suite := []struct {
 		in  string
@@ -33,13 +44,71 @@ func TestParseCacheControl(t *testing.T) {
 		{in: "public, max-age=30", out: New().SetPublic().SetMaxAge(30)},
 		{in: "public, max-age=30, s-maxage=10", out: New().SetPublic().SetMaxAge(30).SetSharedMaxAge(10)},
 		{in: "private, no-cache, no-transform, max-age=30, s-maxage=10", out: New().SetPrivate().SetMaxAge(30).SetSharedMaxAge(10).NoCache().NoTransform()},
+		{in: `no-cache="Set-Cookie, public", max-age=30`, out: New().NoCache().SetMaxAge(30)},
+		{in: "max-age=30;no-store", out: New().SetMaxAge(30).NoStore()},
 	}
 
 	for _, s := range suite {
 		parsed := NewString(s.in)
-		if !reflect.DeepEqual(s.out, parsed) {
-			t.Errorf("TestParse: Expected %s, given %s", s.out, parsed)
+		if !s.out.Equal(parsed) {
+			t.Errorf("TestParse: Expected %s, given %s (%v differ)", s.out, parsed, s.out.Diff(parsed))
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	suite := []struct {
+		a    *Headers
+		b    *Headers
+		diff []string
+	}{
+		{a: New(), b: New()},
+		{a: New().SetPublic().SetMaxAge(30), b: New().SetMaxAge(30).SetPublic()},
+		{a: New().SetMaxAge(0), b: New(), diff: []string{"max-age"}},
+		{a: New().SetMaxAge(30), b: New().SetMaxAge(60), diff: []string{"max-age"}},
+		{a: New().SetPublic().NoCache(), b: New().SetPrivate().NoCache(), diff: []string{"public", "private"}},
+		{a: New().NoStore(), b: New().SetSharedMaxAge(10), diff: []string{"no-store", "s-maxage"}},
+	}
+
+	for i, s := range suite {
+		// Populate one buffer so it is ignored by the comparison.
+		_ = s.a.String()
+
+		if diff := s.a.Diff(s.b); !reflect.DeepEqual(diff, s.diff) {
+			t.Errorf("TestDiff (%d): Expected %v, given %v", i, s.diff, diff)
+		} else if equal := s.a.Equal(s.b); equal != (len(s.diff) == 0) {
+			t.Errorf("TestDiff (%d): Unexpected Equal: %v", i, equal)
+		}
+		Release(s.a)
+		Release(s.b)
+	}
+}
+
+func TestMaxAge(t *testing.T) {
+	suite := []struct {
+		in           string
+		maxAge       int64
+		maxAgeOK     bool
+		sharedMaxAge int64
+		sharedOK     bool
+	}{
+		{in: ""},
+		{in: "public"},
+		{in: "max-age=30", maxAge: 30, maxAgeOK: true},
+		{in: "max-age=0", maxAgeOK: true},
+		{in: "public, max-age=30, s-maxage=10", maxAge: 30, maxAgeOK: true, sharedMaxAge: 10, sharedOK: true},
+		{in: "s-maxage=0", sharedOK: true},
+	}
+
+	for i, s := range suite {
+		h := NewString(s.in)
+		if age, ok := h.MaxAge(); age != s.maxAge || ok != s.maxAgeOK {
+			t.Errorf("TestMaxAge (%d): Expected max-age %d/%t, given %d/%t", i, s.maxAge, s.maxAgeOK, age, ok)
+		}
+		if age, ok := h.SharedMaxAge(); age != s.sharedMaxAge || ok != s.sharedOK {
+			t.Errorf("TestMaxAge (%d): Expected s-maxage %d/%t, given %d/%t", i, s.sharedMaxAge, s.sharedOK, age, ok)
 		}
+		Release(h)
 	}
 }
 
@@ -66,6 +135,39 @@ func TestString(t *testing.T) {
 	}
 }
 
+func TestWriteTo(t *testing.T) {
+	suite := []struct {
+		in  *Headers
+		out []string
+	}{
+		{in: New(), out: nil},
+		{in: New().SetPublic(), out: []string{"public"}},
+		{in: New().SetMaxAge(0), out: []string{"max-age=0"}},
+		{in: New().NoTransform().NoCache().SetPublic(), out: []string{"no-cache, no-transform, public"}},
+	}
+
+	for i, s := range suite {
+		header := http.Header{}
+		s.in.WriteTo(header)
+		if given := header["Cache-Control"]; !reflect.DeepEqual(s.out, given) {
+			t.Errorf("TestWriteTo (%d): Expected %v, given %v", i, s.out, given)
+		}
+		Release(s.in)
+	}
+}
+
+func TestWriteTo_SensibleDefaults(t *testing.T) {
+	h := New()
+	defer Release(h)
+
+	header := http.Header{}
+	h.SensibleDefaults(header, http.StatusOK)
+	h.WriteTo(header)
+	if expected, given := "no-cache, private", header.Get("Cache-Control"); given != expected {
+		t.Errorf("TestWriteTo_SensibleDefaults: Expected %s, given %s", expected, given)
+	}
+}
+
 func TestEmpty(t *testing.T) {
 	suite := []struct {
 		in    *Headers
@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// VaryFields returns the canonical request header names listed in a Vary
+// header, sorted and without duplicates. cacheable is false if Vary
+// contains "*", in which case the response should not be stored.
+func VaryFields(vary string) (fields []string, cacheable bool) {
+	seen := make(map[string]struct{})
+	for _, f := range strings.Split(vary, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		} else if f == "*" {
+			return nil, false
+		}
+
+		f = http.CanonicalHeaderKey(f)
+		if _, ok := seen[f]; ok {
+			continue
+		}
+		seen[f] = struct{}{}
+		fields = append(fields, f)
+	}
+
+	sort.Strings(fields)
+	return fields, true
+}
+
+// VaryKey returns the values of the request headers named in vary encoded
+// as a string, for use as part of a cache key alongside the host and path.
+// Requests with the same VaryKey can be served the same response. An empty
+// string is returned if vary contains "*" or names no headers.
+func VaryKey(r *http.Request, vary string) string {
+	fields, cacheable := VaryFields(vary)
+	if !cacheable || len(fields) == 0 {
+		return ""
+	}
+
+	v := make(url.Values, len(fields))
+	for _, f := range fields {
+		v.Set(f, strings.Join(r.Header[f], ", "))
+	}
+	return v.Encode()
+}
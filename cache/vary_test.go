@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestVaryFields(t *testing.T) {
+	suite := []struct {
+		vary      string
+		fields    []string
+		cacheable bool
+	}{
+		{vary: "", cacheable: true},
+		{vary: "Accept-Encoding", fields: []string{"Accept-Encoding"}, cacheable: true},
+		{vary: "origin, accept-encoding, Origin", fields: []string{"Accept-Encoding", "Origin"}, cacheable: true},
+		{vary: "*"},
+		{vary: "Accept-Encoding, *"},
+	}
+
+	for i, s := range suite {
+		fields, cacheable := VaryFields(s.vary)
+		if !reflect.DeepEqual(fields, s.fields) {
+			t.Errorf("TestVaryFields (%d): Expected %v, given %v", i, s.fields, fields)
+		} else if cacheable != s.cacheable {
+			t.Errorf("TestVaryFields (%d): Expected cacheable %v, given %v", i, s.cacheable, cacheable)
+		}
+	}
+}
+
+func TestVaryKey(t *testing.T) {
+	gzip, _ := http.NewRequest("GET", "/", nil)
+	gzip.Header.Set("Accept-Encoding", "gzip")
+	gzip.Header.Set("Authorization", "Bearer a")
+
+	identity, _ := http.NewRequest("GET", "/", nil)
+	identity.Header.Set("Authorization", "Bearer b")
+
+	if given := VaryKey(gzip, "Accept-Encoding"); given != "Accept-Encoding=gzip" {
+		t.Errorf("TestVaryKey: Expected Accept-Encoding=gzip, given %s", given)
+	}
+	if VaryKey(gzip, "Accept-Encoding") == VaryKey(identity, "Accept-Encoding") {
+		t.Error("TestVaryKey: Expected requests with different encodings to have different keys")
+	}
+	if given := VaryKey(gzip, "*"); given != "" {
+		t.Errorf("TestVaryKey: Expected empty key for Vary: *, given %s", given)
+	}
+}
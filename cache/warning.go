@@ -0,0 +1,16 @@
+package cache
+
+import "strings"
+
+// StaleWarning returns a Warning header value with the 110 (Response is
+// Stale) warn-code from RFC 7234, to be set when serving stale content
+// (i.e. stale-while-revalidate or stale-if-error). reason is used as the
+// warn-text; if empty, "Response is Stale" is used.
+func StaleWarning(reason string) string {
+	if reason == "" {
+		reason = "Response is Stale"
+	}
+	reason = strings.Replace(reason, `\`, `\\`, -1)
+	reason = strings.Replace(reason, `"`, `\"`, -1)
+	return `110 - "` + reason + `"`
+}
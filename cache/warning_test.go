@@ -0,0 +1,21 @@
+package cache
+
+import "testing"
+
+func TestStaleWarning(t *testing.T) {
+	tests := []struct {
+		reason string
+		want   string
+	}{
+		{reason: "", want: `110 - "Response is Stale"`},
+		{reason: "Revalidation in progress", want: `110 - "Revalidation in progress"`},
+		{reason: `origin "api" unavailable`, want: `110 - "origin \"api\" unavailable"`},
+		{reason: `a\b`, want: `110 - "a\\b"`},
+	}
+
+	for i, tt := range tests {
+		if given := StaleWarning(tt.reason); given != tt.want {
+			t.Errorf("(%d): expected %s, given %s", i, tt.want, given)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package kumi
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cristiangraz/kumi/cache"
+)
+
+// CachePolicy sets the Cache-Control header of a response based on its
+// status class. A zero duration marks responses in that class no-store.
+// Server errors are never cached. A Cache-Control header set by the
+// handler always takes precedence.
+type CachePolicy struct {
+	// Public marks cacheable responses as public instead of private.
+	Public bool
+
+	// Success is the max-age for 2xx and 304 Not Modified responses.
+	Success time.Duration
+
+	// Redirect is the max-age for all other 3xx responses.
+	Redirect time.Duration
+
+	// ClientError is the max-age for 4xx responses.
+	ClientError time.Duration
+}
+
+// header returns the Cache-Control header for the status code.
+func (p CachePolicy) header(status int) string {
+	var ttl time.Duration
+	switch {
+	case status >= 200 && status < 300, status == http.StatusNotModified:
+		ttl = p.Success
+	case status >= 300 && status < 400:
+		ttl = p.Redirect
+	case status >= 400 && status < 500:
+		ttl = p.ClientError
+	}
+
+	h := cache.New()
+	defer cache.Release(h)
+	if ttl <= 0 {
+		return h.NoStore().String()
+	}
+
+	if p.Public {
+		h.SetPublic()
+	} else {
+		h.SetPrivate()
+	}
+	return h.SetMaxAge(int64(ttl / time.Second)).String()
+}
+
+// handler wraps fn so the Cache-Control header is set when the status
+// code is written.
+func (p CachePolicy) handler(fn http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rw, ok := w.(ResponseWriter)
+		if !ok {
+			rw = &responseWriter{ResponseWriter: w, status: http.StatusOK}
+		}
+
+		cw := &cachePolicyWriter{ResponseWriter: rw, policy: p}
+		fn(cw, r)
+		if !cw.wroteHeader {
+			cw.WriteHeader(http.StatusOK)
+		}
+	}
+}
+
+// cachePolicyWriter sets the Cache-Control header from a CachePolicy
+// before the status code is written.
+type cachePolicyWriter struct {
+	ResponseWriter
+	policy      CachePolicy
+	wroteHeader bool
+}
+
+// WriteHeader sets the Cache-Control header if the handler has not set
+// one and writes the status code.
+func (w *cachePolicyWriter) WriteHeader(s int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if w.Header().Get("Cache-Control") == "" {
+			w.Header().Set("Cache-Control", w.policy.header(s))
+		}
+	}
+	w.ResponseWriter.WriteHeader(s)
+}
+
+// Write writes the response.
+func (w *cachePolicyWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
@@ -0,0 +1,72 @@
+package kumi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cristiangraz/kumi"
+)
+
+func TestRouterGroup_GetCacheable(t *testing.T) {
+	policy := kumi.CachePolicy{
+		Public:      true,
+		Success:     time.Hour,
+		ClientError: time.Minute,
+	}
+
+	tests := []struct {
+		status       int
+		write        bool
+		cacheControl string
+		expected     string
+	}{
+		{status: http.StatusOK, write: true, expected: "max-age=3600, public"},
+		{status: http.StatusOK, expected: "max-age=3600, public"},
+		{status: http.StatusNotFound, write: true, expected: "max-age=60, public"},
+		{status: http.StatusMovedPermanently, expected: "no-store"},
+		{status: http.StatusInternalServerError, write: true, expected: "no-store"},
+		{status: http.StatusOK, cacheControl: "no-cache", expected: "no-cache"},
+	}
+
+	for i, tt := range tests {
+		k := kumi.New(&Router{})
+		k.GetCacheable("/", policy, func(w http.ResponseWriter, r *http.Request) {
+			if tt.cacheControl != "" {
+				w.Header().Set("Cache-Control", tt.cacheControl)
+			}
+			if tt.write {
+				w.WriteHeader(tt.status)
+				w.Write([]byte("body"))
+			} else if tt.status != http.StatusOK {
+				w.WriteHeader(tt.status)
+			}
+		})
+
+		r, _ := http.NewRequest("GET", "/", nil)
+		w := httptest.NewRecorder()
+		k.ServeHTTP(w, r)
+
+		if w.Code != tt.status {
+			t.Fatalf("TestRouterGroup_GetCacheable (%d): Expected status %d, given %d", i, tt.status, w.Code)
+		} else if cc := w.Header().Get("Cache-Control"); cc != tt.expected {
+			t.Fatalf("TestRouterGroup_GetCacheable (%d): Expected Cache-Control %q, given %q", i, tt.expected, cc)
+		}
+	}
+}
+
+func TestRouterGroup_GetCacheableHead(t *testing.T) {
+	k := kumi.New(&Router{})
+	k.GetCacheable("/", kumi.CachePolicy{Success: time.Minute}, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("body"))
+	})
+
+	r, _ := http.NewRequest("HEAD", "/", nil)
+	w := httptest.NewRecorder()
+	k.ServeHTTP(w, r)
+
+	if cc := w.Header().Get("Cache-Control"); cc != "max-age=60, private" {
+		t.Fatalf("unexpected Cache-Control: %q", cc)
+	}
+}
@@ -2,8 +2,15 @@ package kumi
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"net/http"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cristiangraz/kumi/api"
+	"github.com/xeipuuv/gojsonschema"
 )
 
 // RequestContext returns route params and query params for the
@@ -11,6 +18,29 @@ import (
 type RequestContext interface {
 	Params() Params
 	Query() *Query
+
+	// RequestID returns the ID for the current request. If no ID has
+	// been set, one is generated on first access.
+	RequestID() string
+
+	// Done, Err, and Canceled report on the request's context.Context as
+	// it was when kumi received the request. It is canceled when the client
+	// disconnects or the server shuts down.
+	Done() <-chan struct{}
+	Err() error
+	Canceled() bool
+
+	// StartSpan starts timing a named span. The span's duration is
+	// recorded when the returned func is called.
+	StartSpan(name string) func()
+
+	// Spans returns the spans recorded for the request in the order
+	// they finished.
+	Spans() []Span
+
+	// Negotiate sends result with the formatter matching the request's
+	// Accept header. See api.NegotiateFormatter.
+	Negotiate(w http.ResponseWriter, status int, result interface{})
 }
 
 type key int
@@ -18,6 +48,9 @@ type key int
 const (
 	contextKey key = iota
 	paramsKey
+	geoKey
+	requestIDKey
+	routeKey
 )
 
 // Context retrieves the request context.
@@ -44,9 +77,96 @@ func getParams(r *http.Request) (Params, bool) {
 	return p, ok
 }
 
+// route describes the route matching a request.
+type route struct {
+	pattern string
+	schema  gojsonschema.JSONLoader
+}
+
+// withRoute sets the route in the context for RoutePattern and
+// RouteResponseSchema.
+func withRoute(rt *route, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), routeKey, rt)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RoutePattern returns the pattern of the route matching the request,
+// including any group path (i.e. /users/:id). An empty string is returned
+// if no route matched, such as in a NotFound handler.
+func RoutePattern(r *http.Request) string {
+	if rt, ok := r.Context().Value(routeKey).(*route); ok {
+		return rt.pattern
+	}
+	return ""
+}
+
+// RouteResponseSchema returns the response schema registered with
+// GetWithSchema for the route matching the request, if any.
+func RouteResponseSchema(r *http.Request) (gojsonschema.JSONLoader, bool) {
+	if rt, ok := r.Context().Value(routeKey).(*route); ok && rt.schema != nil {
+		return rt.schema, true
+	}
+	return nil, false
+}
+
+// SetRequestID sets the ID for the request, i.e. one received from an
+// upstream service. RequestContext.RequestID returns the same ID.
+func SetRequestID(r *http.Request, id string) *http.Request {
+	if rc, ok := r.Context().Value(contextKey).(*requestContext); ok {
+		rc.requestID = id
+	}
+	ctx := context.WithValue(r.Context(), requestIDKey, id)
+	return r.WithContext(ctx)
+}
+
+// RequestID returns the ID for the request. It returns the ID set with
+// SetRequestID, otherwise kumi's RequestContext generates one. An empty
+// string is returned outside of kumi if no ID has been set.
+func RequestID(r *http.Request) string {
+	if id, ok := r.Context().Value(requestIDKey).(string); ok {
+		return id
+	} else if rc, ok := r.Context().Value(contextKey).(RequestContext); ok {
+		return rc.RequestID()
+	}
+	return ""
+}
+
+// GeoInfo holds geographic information about the client.
+type GeoInfo struct {
+	Country string
+	Region  string
+}
+
+// SetGeo sets GeoInfo in the context for Geo to access.
+func SetGeo(r *http.Request, g GeoInfo) *http.Request {
+	ctx := context.WithValue(r.Context(), geoKey, g)
+	return r.WithContext(ctx)
+}
+
+// Geo returns the GeoInfo set for the request, if any.
+func Geo(r *http.Request) (GeoInfo, bool) {
+	g, ok := r.Context().Value(geoKey).(GeoInfo)
+	return g, ok
+}
+
 type requestContext struct {
-	params Params
-	query  *Query
+	ctx       context.Context
+	request   *http.Request
+	params    Params
+	query     *Query
+	requestID string
+
+	mu    sync.Mutex
+	spans []Span
+
+	body         []byte
+	bodyBuffered bool
+
+	// refs counts the holders of the context. It is returned to the
+	// pool when the last one releases it.
+	refs int32
 }
 
 var _ RequestContext = &requestContext{}
@@ -61,6 +181,84 @@ func (r *requestContext) Query() *Query {
 	return r.query
 }
 
+// RequestID returns the request ID, generating one if it has not been set.
+func (r *requestContext) RequestID() string {
+	if r.requestID == "" {
+		r.requestID = NewRequestID()
+	}
+	return r.requestID
+}
+
+// Done returns a channel that is closed when the request is canceled.
+func (r *requestContext) Done() <-chan struct{} {
+	return r.ctx.Done()
+}
+
+// Err returns the reason the request was canceled, if any.
+func (r *requestContext) Err() error {
+	return r.ctx.Err()
+}
+
+// Canceled returns true if the request has been canceled.
+func (r *requestContext) Canceled() bool {
+	return r.ctx.Err() != nil
+}
+
+// StartSpan starts timing a named span.
+func (r *requestContext) StartSpan(name string) func() {
+	start := time.Now()
+	return func() {
+		d := time.Since(start)
+		r.mu.Lock()
+		r.spans = append(r.spans, Span{Name: name, Duration: d})
+		r.mu.Unlock()
+	}
+}
+
+// Spans returns a copy of the spans recorded for the request.
+func (r *requestContext) Spans() []Span {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.spans) == 0 {
+		return nil
+	}
+	return append([]Span(nil), r.spans...)
+}
+
+// Negotiate sends result with the formatter matching the request's Accept
+// header. A status below 400 sends a success response holding result. A
+// status of 400 or above sends a failure response. result may be an
+// api.Error or []api.Error to list the errors; any other value is sent
+// as the failure's result.
+func (r *requestContext) Negotiate(w http.ResponseWriter, status int, result interface{}) {
+	f := api.NegotiateFormatter(r.request)
+	if status < http.StatusBadRequest {
+		resp := api.Success(result)
+		resp.Status = status
+		resp.SendFormat(w, f)
+		return
+	}
+
+	switch v := result.(type) {
+	case api.Error:
+		api.Failure(status, v).SendFormat(w, f)
+	case []api.Error:
+		api.Failure(status, v...).SendFormat(w, f)
+	default:
+		resp := api.Failure(status)
+		resp.Result = result
+		resp.SendFormat(w, f)
+	}
+}
+
+// NewRequestID generates a random 128-bit hex encoded ID.
+func NewRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
 var requestContextPool = &sync.Pool{
 	New: func() interface{} {
 		return &requestContext{}
@@ -70,13 +268,47 @@ var requestContextPool = &sync.Pool{
 // newRequestContext returns a new RequestContext from a sync.Pool.
 func newRequestContext(r *http.Request) *requestContext {
 	rc := requestContextPool.Get().(*requestContext)
-	rc.params = nil
-	rc.query = &Query{request: r}
+	rc.reset(r)
 
 	return rc
 }
 
-// returnContext returns the RequestContext to the sync.Pool.
+// reset prepares a pooled requestContext for r. Every field must be
+// reset here so nothing leaks from a previous request.
+func (rc *requestContext) reset(r *http.Request) {
+	rc.ctx = r.Context()
+	rc.request = r
+	rc.params = nil
+	rc.query = &Query{request: r}
+	rc.requestID = ""
+	rc.spans = rc.spans[:0]
+	rc.body = nil
+	rc.bodyBuffered = false
+	rc.refs = 1
+}
+
+// returnContext returns the RequestContext to the sync.Pool once every
+// holder has released it.
 func returnContext(rc *requestContext) {
-	requestContextPool.Put(rc)
+	if atomic.AddInt32(&rc.refs, -1) == 0 {
+		requestContextPool.Put(rc)
+	}
+}
+
+// RetainContext keeps kumi's RequestContext for r from being reused by
+// another request until the returned func is called. Middleware that
+// runs the handler in a goroutine which may outlive the request, such as
+// Timeout, must retain the context for the life of the goroutine. The
+// returned func is safe to call more than once.
+func RetainContext(r *http.Request) (release func()) {
+	rc, ok := r.Context().Value(contextKey).(*requestContext)
+	if !ok {
+		return func() {}
+	}
+
+	atomic.AddInt32(&rc.refs, 1)
+	var once sync.Once
+	return func() {
+		once.Do(func() { returnContext(rc) })
+	}
 }
@@ -1,11 +1,16 @@
 package kumi_test
 
 import (
+	"context"
+	"encoding/xml"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/cristiangraz/kumi"
+	"github.com/cristiangraz/kumi/api"
 )
 
 // Test a custom context without any panics.
@@ -22,3 +27,229 @@ func TestContext(t *testing.T) {
 
 	k.ServeHTTP(w, r)
 }
+
+func TestContext_PoolReset(t *testing.T) {
+	var given []kumi.Params
+	var ids []string
+	k := kumi.New(&Router{})
+	k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		rc := kumi.Context(r)
+		given = append(given, rc.Params())
+		if len(ids) == 0 {
+			ids = append(ids, rc.RequestID())
+		} else if id := rc.RequestID(); id == ids[0] {
+			t.Fatalf("request ID leaked from a prior request: %s", id)
+		}
+	})
+
+	// Pooled contexts are reused by sequential requests.
+	for i := 0; i < 10; i++ {
+		r, _ := http.NewRequest("GET", "/", nil)
+		if i == 0 {
+			r = kumi.SetParams(r, kumi.Params{"id": "1"})
+		}
+		k.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	if given[0].Get("id") != "1" {
+		t.Fatalf("expected params for the first request, given %v", given[0])
+	}
+	for i, p := range given[1:] {
+		if p != nil {
+			t.Fatalf("(%d): params leaked from a prior request: %v", i+1, p)
+		}
+	}
+}
+
+func TestContext_Negotiate(t *testing.T) {
+	type user struct {
+		XMLName xml.Name `xml:"user" json:"-"`
+		Name    string   `json:"name" xml:"name"`
+	}
+
+	k := kumi.New(&Router{})
+	k.Post("/users", func(w http.ResponseWriter, r *http.Request) {
+		kumi.Context(r).Negotiate(w, http.StatusCreated, user{Name: "kumi"})
+	})
+	k.Get("/error", func(w http.ResponseWriter, r *http.Request) {
+		kumi.Context(r).Negotiate(w, http.StatusNotFound, api.Error{Type: "not_found", Message: "Not found"})
+	})
+
+	tests := []struct {
+		method      string
+		path        string
+		accept      string
+		status      int
+		contentType string
+		body        string
+	}{
+		{
+			method:      "POST",
+			path:        "/users",
+			accept:      "application/json",
+			status:      http.StatusCreated,
+			contentType: "application/json",
+			body:        `{"success":true,"result":{"name":"kumi"}}`,
+		},
+		{
+			method:      "POST",
+			path:        "/users",
+			accept:      "application/xml",
+			status:      http.StatusCreated,
+			contentType: "application/xml",
+			body:        `<response><success>true</success><user><name>kumi</name></user></response>`,
+		},
+		{
+			method:      "GET",
+			path:        "/error",
+			accept:      "application/json",
+			status:      http.StatusNotFound,
+			contentType: "application/json",
+			body:        `{"success":false,"status":404,"code":"not_found","errors":[{"type":"not_found","message":"Not found"}]}`,
+		},
+	}
+
+	for i, tt := range tests {
+		r, _ := http.NewRequest(tt.method, tt.path, nil)
+		r.Header.Set("Accept", tt.accept)
+		w := httptest.NewRecorder()
+		k.ServeHTTP(w, r)
+
+		if w.Code != tt.status {
+			t.Fatalf("(%d): unexpected status code: %d", i, w.Code)
+		} else if ct := w.Header().Get("Content-Type"); ct != tt.contentType {
+			t.Fatalf("(%d): unexpected Content-Type: %s", i, ct)
+		} else if body := strings.TrimSpace(w.Body.String()); body != tt.body {
+			t.Fatalf("(%d): unexpected body: %s", i, body)
+		}
+	}
+}
+
+func TestContext_RequestID(t *testing.T) {
+	var ids []string
+	k := kumi.New(&Router{})
+	k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		id := kumi.Context(r).RequestID()
+		if id == "" {
+			t.Fatal("expected request id")
+		} else if got := kumi.Context(r).RequestID(); got != id {
+			t.Fatalf("expected stable request id: %s, %s", id, got)
+		}
+		ids = append(ids, id)
+	})
+
+	for i := 0; i < 2; i++ {
+		r, _ := http.NewRequest("GET", "/", nil)
+		w := httptest.NewRecorder()
+		k.ServeHTTP(w, r)
+	}
+
+	if len(ids) != 2 {
+		t.Fatalf("unexpected number of requests: %d", len(ids))
+	} else if ids[0] == ids[1] {
+		t.Fatalf("expected unique request ids per request: %s", ids[0])
+	}
+}
+
+func TestContext_Canceled(t *testing.T) {
+	var ran bool
+	ctx, cancel := context.WithCancel(context.Background())
+	k := kumi.New(&Router{})
+	k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		ran = true
+		rc := kumi.Context(r)
+		if rc.Canceled() {
+			t.Fatal("expected request not to be canceled")
+		} else if rc.Err() != nil {
+			t.Fatalf("unexpected error: %v", rc.Err())
+		}
+
+		cancel()
+		select {
+		case <-rc.Done():
+		default:
+			t.Fatal("expected Done to be closed")
+		}
+
+		if !rc.Canceled() {
+			t.Fatal("expected request to be canceled")
+		} else if rc.Err() != context.Canceled {
+			t.Fatalf("unexpected error: %v", rc.Err())
+		}
+	})
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	k.ServeHTTP(httptest.NewRecorder(), r.WithContext(ctx))
+
+	if !ran {
+		t.Fatal("handler did not run")
+	}
+}
+
+func TestContext_Spans(t *testing.T) {
+	k := kumi.New(&Router{})
+	k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		rc := kumi.Context(r)
+		if spans := rc.Spans(); spans != nil {
+			t.Fatalf("unexpected spans: %#v", spans)
+		}
+
+		end := rc.StartSpan("db")
+		time.Sleep(time.Millisecond)
+		end()
+		rc.StartSpan("render")()
+
+		spans := rc.Spans()
+		if len(spans) != 2 {
+			t.Fatalf("unexpected spans: %#v", spans)
+		} else if spans[0].Name != "db" || spans[0].Duration < time.Millisecond {
+			t.Fatalf("unexpected span: %#v", spans[0])
+		} else if spans[1].Name != "render" {
+			t.Fatalf("unexpected span: %#v", spans[1])
+		}
+
+		kumi.SetServerTiming(w, r)
+	})
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	k.ServeHTTP(w, r)
+
+	if h := w.Header().Get("Server-Timing"); !strings.HasPrefix(h, "db;dur=") || !strings.Contains(h, ", render;dur=") {
+		t.Fatalf("unexpected Server-Timing header: %s", h)
+	}
+}
+
+func TestServerTiming(t *testing.T) {
+	spans := []kumi.Span{
+		{Name: "db", Duration: 12500 * time.Microsecond},
+		{Name: "render", Duration: 3 * time.Millisecond},
+	}
+
+	if h := kumi.ServerTiming(spans); h != "db;dur=12.5, render;dur=3" {
+		t.Fatalf("unexpected Server-Timing header: %s", h)
+	}
+}
+
+func TestRoutePattern(t *testing.T) {
+	var given string
+	k := kumi.New(&Router{})
+	k.GroupPath("/users").Get("/:id", func(w http.ResponseWriter, r *http.Request) {
+		given = kumi.RoutePattern(r)
+	})
+	k.NotFoundHandler(func(w http.ResponseWriter, r *http.Request) {
+		given = kumi.RoutePattern(r)
+	})
+
+	r, _ := http.NewRequest("GET", "/users/:id", nil)
+	k.ServeHTTP(httptest.NewRecorder(), r)
+	if given != "/users/:id" {
+		t.Fatalf("TestRoutePattern: Expected %q, given %q", "/users/:id", given)
+	}
+
+	r, _ = http.NewRequest("GET", "/missing", nil)
+	k.ServeHTTP(httptest.NewRecorder(), r)
+	if given != "" {
+		t.Fatalf("TestRoutePattern: Expected no pattern, given %q", given)
+	}
+}
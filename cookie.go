@@ -0,0 +1,38 @@
+package kumi
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrCookieInvalid is returned by SetCookie when the cookie cannot be
+	// serialized, such as when it has an invalid name.
+	ErrCookieInvalid = errors.New("cookie: invalid cookie")
+
+	// ErrCookieNotSecure is returned by SetCookie when a cookie with
+	// SameSite=None or the Partitioned attribute is not Secure.
+	ErrCookieNotSecure = errors.New("cookie: SameSite=None and Partitioned cookies must be Secure")
+)
+
+// SetCookie adds a Set-Cookie header to the response. If partitioned is
+// true the Partitioned attribute (CHIPS) is added, which http.Cookie does
+// not support before Go 1.23. Cookies with SameSite=None or Partitioned
+// must also be Secure.
+func SetCookie(w http.ResponseWriter, c *http.Cookie, partitioned bool) error {
+	if (c.SameSite == http.SameSiteNoneMode || partitioned) && !c.Secure {
+		return ErrCookieNotSecure
+	}
+
+	v := c.String()
+	if v == "" {
+		return ErrCookieInvalid
+	}
+	if partitioned && !strings.Contains(v, "; Partitioned") {
+		v += "; Partitioned"
+	}
+
+	w.Header().Add("Set-Cookie", v)
+	return nil
+}
@@ -0,0 +1,50 @@
+package kumi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cristiangraz/kumi"
+)
+
+func TestSetCookie(t *testing.T) {
+	tests := []struct {
+		cookie      *http.Cookie
+		partitioned bool
+		want        string
+		err         error
+	}{
+		{
+			cookie:      &http.Cookie{Name: "session", Value: "abc", Path: "/", Secure: true, HttpOnly: true, SameSite: http.SameSiteNoneMode},
+			partitioned: true,
+			want:        "session=abc; Path=/; HttpOnly; Secure; SameSite=None; Partitioned",
+		},
+		{
+			cookie: &http.Cookie{Name: "session", Value: "abc", SameSite: http.SameSiteLaxMode},
+			want:   "session=abc; SameSite=Lax",
+		},
+		{
+			cookie: &http.Cookie{Name: "session", Value: "abc", SameSite: http.SameSiteNoneMode},
+			err:    kumi.ErrCookieNotSecure,
+		},
+		{
+			cookie:      &http.Cookie{Name: "session", Value: "abc"},
+			partitioned: true,
+			err:         kumi.ErrCookieNotSecure,
+		},
+		{
+			cookie: &http.Cookie{Name: "bad name", Value: "abc"},
+			err:    kumi.ErrCookieInvalid,
+		},
+	}
+
+	for i, tt := range tests {
+		w := httptest.NewRecorder()
+		if err := kumi.SetCookie(w, tt.cookie, tt.partitioned); err != tt.err {
+			t.Fatalf("TestSetCookie (%d): Expected error %v, given %v", i, tt.err, err)
+		} else if given := w.Header().Get("Set-Cookie"); given != tt.want {
+			t.Fatalf("TestSetCookie (%d): Expected %q, given %q", i, tt.want, given)
+		}
+	}
+}
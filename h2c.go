@@ -0,0 +1,23 @@
+package kumi
+
+import (
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// enableH2C wraps the server's handler to accept h2c connections. The
+// http2.Server is registered with the http.Server so that Shutdown also
+// gracefully closes h2c connections, which net/http does not track once
+// they are hijacked.
+func (s *Server) enableH2C() error {
+	if s.Transport != nil || s.Server.TLSConfig != nil {
+		return nil
+	}
+
+	h2s := &http2.Server{}
+	if err := http2.ConfigureServer(s.Server, h2s); err != nil {
+		return err
+	}
+	s.Server.Handler = h2c.NewHandler(s.Server.Handler, h2s)
+	return nil
+}
@@ -0,0 +1,69 @@
+package kumi_test
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/cristiangraz/kumi"
+	"golang.org/x/net/http2"
+)
+
+func TestEngine_ServeH2C(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var proto int
+	k := kumi.New(&Router{})
+	k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		proto = r.ProtoMajor
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errch := make(chan error, 1)
+	go func() {
+		errch <- k.Serve(&kumi.ServeConfig{
+			Context:          ctx,
+			InterruptTimeout: time.Second,
+			ContextTimeout:   time.Second,
+			Servers:          []kumi.Server{{Server: &http.Server{}, Listener: l, H2C: true}},
+		})
+	}()
+
+	client := &http.Client{
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, addr)
+			},
+		},
+	}
+	resp, err := client.Get("http://" + l.Addr().String() + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("unexpected status code: %d", resp.StatusCode)
+	} else if proto != 2 || resp.ProtoMajor != 2 {
+		t.Fatalf("expected HTTP/2, given request HTTP/%d and response HTTP/%d", proto, resp.ProtoMajor)
+	}
+
+	cancel()
+	select {
+	case err := <-errch:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected Serve to shut down")
+	}
+}
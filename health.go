@@ -0,0 +1,36 @@
+package kumi
+
+import (
+	"net/http"
+
+	"github.com/justinas/alice"
+)
+
+// Health registers GET routes for liveness and readiness probes. The
+// liveness route always responds with 200 OK. The readiness route
+// responds with 200 OK when ready returns true, otherwise 503 Service
+// Unavailable. A nil ready func is always ready. The readiness route also
+// responds with 503 once the Engine is draining.
+//
+// The routes skip any middleware added with Use, whether it was added
+// before or after Health is called, so that authentication or rate
+// limiting cannot block health checks.
+func (e *Engine) Health(livePath, readyPath string, ready func() bool) {
+	g := e.RouterGroup
+	if rg, ok := e.RouterGroup.(*routerGroup); ok {
+		g = &routerGroup{router: rg.router, middleware: alice.New(setup), registered: rg.registered}
+	}
+
+	g.Get(livePath, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+	})
+	g.Get(readyPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		if e.Draining() || (ready != nil && !ready()) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
@@ -0,0 +1,46 @@
+package kumi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cristiangraz/kumi"
+)
+
+func TestEngine_Health(t *testing.T) {
+	var ready bool
+	k := kumi.New(&Router{})
+	k.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		})
+	})
+	k.Health("/healthz", "/readyz", func() bool { return ready })
+
+	tests := []struct {
+		path   string
+		ready  bool
+		status int
+	}{
+		{path: "/healthz", status: http.StatusOK},
+		{path: "/readyz", status: http.StatusServiceUnavailable},
+		{path: "/readyz", ready: true, status: http.StatusOK},
+		{path: "/healthz", ready: true, status: http.StatusOK},
+		{path: "/readyz", status: http.StatusServiceUnavailable},
+	}
+
+	for i, tt := range tests {
+		ready = tt.ready
+
+		r, _ := http.NewRequest("GET", tt.path, nil)
+		w := httptest.NewRecorder()
+		k.ServeHTTP(w, r)
+
+		if w.Code != tt.status {
+			t.Fatalf("TestEngine_Health (%d): Expected status %d, given %d", i, tt.status, w.Code)
+		} else if given := w.Header().Get("Cache-Control"); given != "no-store" {
+			t.Fatalf("TestEngine_Health (%d): Expected Cache-Control no-store, given %q", i, given)
+		}
+	}
+}
@@ -4,21 +4,37 @@ import (
 	"context"
 	"crypto/tls"
 	"errors"
+	"fmt"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
+	"github.com/cristiangraz/kumi/api"
 	"github.com/justinas/alice"
+	"github.com/xeipuuv/gojsonschema"
 )
 
 // Engine embeds RouterGroup and provides methods to start the server.
 type Engine struct {
 	RouterGroup
+
+	// rejectMethods holds HTTP methods that are rejected before routing.
+	rejectMethods map[string]struct{}
+
+	// draining is set to 1 once Serve begins shutting down.
+	draining int32
+
+	// onShutdown holds the hooks run during a graceful shutdown.
+	onShutdown []func(ctx context.Context)
+
+	// onValidate holds additional checks run by Validate.
+	onValidate []func() error
 }
 
 // New creates a new Engine using the given Router.
@@ -27,12 +43,42 @@ func New(r Router) *Engine {
 		RouterGroup: &routerGroup{
 			router:     r,
 			middleware: alice.New(setup),
+			schemas:    make(map[string]gojsonschema.JSONLoader),
+			registered: make(map[string]bool),
 		},
 	}
 }
 
-// Run starts kumi.
+// Validate checks the Engine for common setup problems so they surface
+// before serving rather than at request time.
+func (e *Engine) Validate() error {
+	if e.RouterGroup == nil {
+		return errors.New("router required")
+	} else if g, ok := e.RouterGroup.(*routerGroup); ok && g.router == nil {
+		return errors.New("router required")
+	} else if api.Formatter == nil {
+		return errors.New("api.Formatter required")
+	}
+
+	for _, fn := range e.onValidate {
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OnValidate registers fn to run when the Engine is validated, such as
+// validator.Registry.Compile to check every JSON schema at startup.
+func (e *Engine) OnValidate(fn func() error) {
+	e.onValidate = append(e.onValidate, fn)
+}
+
+// Run validates the Engine and starts kumi.
 func (e *Engine) Run(addr string) error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
 	return e.Serve(&ServeConfig{
 		Context:          context.Background(),
 		InterruptTimeout: 5 * time.Second,
@@ -43,8 +89,11 @@ func (e *Engine) Run(addr string) error {
 	})
 }
 
-// RunTLS starts kumi with a given TLS config.
+// RunTLS validates the Engine and starts kumi with a given TLS config.
 func (e *Engine) RunTLS(addr string, config *tls.Config) error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
 	return e.Serve(&ServeConfig{
 		Context:          context.Background(),
 		InterruptTimeout: 5 * time.Second,
@@ -63,20 +112,74 @@ type ServeConfig struct {
 	InterruptTimeout time.Duration
 	ContextTimeout   time.Duration
 	Servers          []Server
+
+	// ShutdownHookLimit is the number of OnShutdown hooks run at once.
+	// If zero, 4 hooks run at once.
+	ShutdownHookLimit int
 }
 
+// defaultShutdownHookLimit is the number of shutdown hooks run at once
+// if ServeConfig.ShutdownHookLimit is not set.
+const defaultShutdownHookLimit = 4
+
 type Server struct {
 	Server   *http.Server
 	Listener net.Listener
+
+	// H2C serves HTTP/2 over cleartext (h2c) alongside HTTP/1.1, such as
+	// for service-to-service traffic behind a load balancer that
+	// terminates TLS. It is ignored for servers with a TLSConfig.
+	H2C bool
+
+	// Transport serves Server's handler in place of the http.Server, such
+	// as the HTTP/3 server created by quic.NewServer. Its handler should
+	// call Server.Handler when a request is served, since Engine.Serve
+	// sets the handler after the Server is created.
+	Transport Transport
+}
+
+// Transport is implemented by servers that serve an http.Server's handler
+// without using net/http.
+type Transport interface {
+	ListenAndServe() error
+	Shutdown(ctx context.Context) error
+	Close() error
 }
 
 func (s *Server) serve() error {
-	if s.Listener != nil {
+	if s.Transport != nil {
+		return s.Transport.ListenAndServe()
+	} else if s.Listener != nil {
 		return s.Server.Serve(s.Listener)
 	}
 	return s.Server.ListenAndServe()
 }
 
+func (s *Server) shutdown(ctx context.Context) error {
+	if s.Transport != nil {
+		return s.Transport.Shutdown(ctx)
+	}
+	return s.Server.Shutdown(ctx)
+}
+
+// onListenersClosed calls fn once Shutdown has closed the server's
+// listeners. Servers with a Transport can't report when their listeners
+// close, so fn is called immediately.
+func (s *Server) onListenersClosed(fn func()) {
+	if s.Transport != nil {
+		fn()
+		return
+	}
+	s.Server.RegisterOnShutdown(fn)
+}
+
+func (s *Server) close() error {
+	if s.Transport != nil {
+		return s.Transport.Close()
+	}
+	return s.Server.Close()
+}
+
 // Serve takes one or more http.Server structs and serves those.
 // The handler will be set if one is not provided.
 func (e *Engine) Serve(config *ServeConfig) error {
@@ -90,36 +193,61 @@ func (e *Engine) Serve(config *ServeConfig) error {
 		return errors.New("one or more Servers required")
 	}
 
-	// Run servers.
-	errch := make(chan error)
+	// Run servers. http.ErrServerClosed is the result of a normal shutdown
+	// and is not reported.
+	errch := make(chan error, len(config.Servers))
 	for i := range config.Servers {
 		if config.Servers[i].Server.Handler == nil {
-			config.Servers[i].Server.Handler = e.RouterGroup
+			config.Servers[i].Server.Handler = e
+		}
+		if config.Servers[i].H2C {
+			if err := config.Servers[i].enableH2C(); err != nil {
+				return err
+			}
 		}
 		go func(server Server) {
-			if err := server.serve(); err != nil {
-				errch <- err
+			err := server.serve()
+			if err == http.ErrServerClosed {
+				err = nil
 			}
+			errch <- err
 		}(config.Servers[i])
 	}
 
+	// done receives the first serve error, or nil once every server
+	// has been shut down.
+	done := make(chan error, 1)
+	go func() {
+		for range config.Servers {
+			if err := <-errch; err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+
 	// Wait for signal.
 	graceful := make(chan os.Signal, 1)
 	stop := make(chan os.Signal, 1)
 	signal.Notify(graceful, syscall.SIGINT, syscall.SIGTERM)
 	signal.Notify(stop, syscall.SIGKILL, syscall.SIGQUIT)
+	defer signal.Stop(graceful)
+	defer signal.Stop(stop)
 
 	var ctx context.Context
 	var cancel context.CancelFunc
 	select {
-	case err := <-errch:
+	case err := <-done:
 		return err
 	case <-graceful: // Signal received. Use parent context.
+		atomic.StoreInt32(&e.draining, 1)
 		ctx, cancel = context.WithTimeout(config.Context, config.InterruptTimeout)
 	case <-config.Context.Done(): // Context done. Stop immediately or gracefully shutdown.
+		atomic.StoreInt32(&e.draining, 1)
 		if config.InterruptTimeout == 0 { // Stop immediately.
 			for i := range config.Servers {
-				config.Servers[i].Server.Close()
+				config.Servers[i].close()
 			}
 			return nil
 		}
@@ -128,37 +256,215 @@ func (e *Engine) Serve(config *ServeConfig) error {
 		// set a limit on graceful shutdown.
 		ctx, cancel = context.WithTimeout(context.Background(), config.ContextTimeout)
 	case <-stop: // Stop immediately.
-		for _, server := range config.Servers {
-			server.Server.Close()
+		atomic.StoreInt32(&e.draining, 1)
+		for i := range config.Servers {
+			config.Servers[i].close()
 		}
 		return nil
 	}
 	defer cancel()
 
-	// Graceful shutdown.
+	// Graceful shutdown. Shutdown returns as soon as a server's
+	// connections are idle, so Serve only waits for the full timeout
+	// if connections are still active.
 	var wg sync.WaitGroup
-	for _, server := range config.Servers {
+	closed := make([]chan struct{}, len(config.Servers))
+	for i, server := range config.Servers {
+		ch := make(chan struct{})
+		var once sync.Once
+		server.onListenersClosed(func() { once.Do(func() { close(ch) }) })
+		closed[i] = ch
+
 		wg.Add(1)
-		go func(server *http.Server) {
+		go func(server Server) {
 			defer wg.Done()
-			server.Shutdown(ctx) // Graceful shutdown. Go 1.8 only.
-		}(server.Server)
+			server.shutdown(ctx) // Graceful shutdown. Go 1.8 only.
+		}(server)
 	}
 
-	// Listen for second signal.
+	// Shutdown hooks run once every server has stopped accepting
+	// connections, alongside the drain.
+	hooks := &shutdownHooks{limit: config.ShutdownHookLimit}
+	hooksDone := make(chan struct{})
+	go func() {
+		defer close(hooksDone)
+		for _, ch := range closed {
+			select {
+			case <-ch:
+			case <-ctx.Done():
+			}
+		}
+		hooks.run(ctx, e.onShutdown)
+	}()
+
+	stopped := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(stopped)
+	}()
+	drained := make(chan struct{})
 	go func() {
+		<-stopped
+		<-hooksDone
+		close(drained)
+	}()
+
+	// A second signal cuts the graceful shutdown short. Once ctx is done
+	// the servers return promptly, but hooks are no longer waited on.
+	select {
+	case <-drained:
+	case <-graceful:
+		cancel()
+	case <-stop:
+		cancel()
+	case <-ctx.Done():
+	}
+	<-stopped
+
+	return hooks.err()
+}
+
+// shutdownHooks runs OnShutdown hooks with bounded concurrency and
+// collects their failures.
+type shutdownHooks struct {
+	limit int
+
+	mu   sync.Mutex
+	errs []error
+}
+
+// run runs fns, at most limit at a time, and waits for them to return.
+// Hooks not yet started when ctx is done are skipped.
+func (h *shutdownHooks) run(ctx context.Context, fns []func(context.Context)) {
+	limit := h.limit
+	if limit <= 0 {
+		limit = defaultShutdownHookLimit
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, limit)
+	for i, fn := range fns {
 		select {
-		case <-graceful:
-			cancel()
-		case <-stop:
-			cancel()
+		case sem <- struct{}{}:
 		case <-ctx.Done():
+			h.fail(fmt.Errorf("shutdown hook %d skipped: %v", i, ctx.Err()))
+			continue
 		}
-	}()
 
+		wg.Add(1)
+		go func(i int, fn func(context.Context)) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			defer func() {
+				if p := recover(); p != nil {
+					h.fail(fmt.Errorf("shutdown hook %d panicked: %v", i, p))
+				}
+			}()
+			fn(ctx)
+		}(i, fn)
+	}
 	wg.Wait()
+}
 
-	return nil
+func (h *shutdownHooks) fail(err error) {
+	h.mu.Lock()
+	h.errs = append(h.errs, err)
+	h.mu.Unlock()
+}
+
+// err returns the failures so far, or nil if there are none.
+func (h *shutdownHooks) err() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.errs) == 0 {
+		return nil
+	}
+	return &ShutdownError{Errors: append([]error(nil), h.errs...)}
+}
+
+// ShutdownError is returned by Serve when OnShutdown hooks panic or are
+// skipped because the shutdown context is done.
+type ShutdownError struct {
+	Errors []error
+}
+
+// Error implements the error interface.
+func (e *ShutdownError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// OnShutdown registers fn to run during a graceful shutdown, such as to
+// flush metrics or close database pools. Hooks start once every server
+// has closed its listeners and run concurrently, limited by
+// ServeConfig.ShutdownHookLimit. They receive the shutdown context;
+// hooks not started before it is done are skipped. A panicking hook is
+// recovered, and Serve returns a *ShutdownError listing any hooks that
+// panicked or were skipped. Register hooks before calling Serve.
+func (e *Engine) OnShutdown(fn func(ctx context.Context)) {
+	e.onShutdown = append(e.onShutdown, fn)
+}
+
+// Draining returns true once Serve has started shutting down, so a
+// readiness check can fail while in-flight requests finish.
+func (e *Engine) Draining() bool {
+	return atomic.LoadInt32(&e.draining) == 1
+}
+
+// AllowedMethods returns the sorted methods with a route at path,
+// including the HEAD and OPTIONS routes kumi adds automatically. It can
+// be used to set the Allow header in custom 405 or OPTIONS handlers.
+func (e *Engine) AllowedMethods(path string) []string {
+	return allowedMethods(e.RouterGroup, path)
+}
+
+// ResponseSchemas returns a copy of the response schemas registered with
+// GetWithSchema, keyed by route pattern, i.e. to publish the API's
+// contracts. The ResponseSchema middleware validates responses against
+// them during development.
+func (e *Engine) ResponseSchemas() map[string]gojsonschema.JSONLoader {
+	g, ok := e.RouterGroup.(*routerGroup)
+	if !ok {
+		return nil
+	}
+
+	schemas := make(map[string]gojsonschema.JSONLoader, len(g.schemas))
+	for pattern, schema := range g.schemas {
+		schemas[pattern] = schema
+	}
+	return schemas
+}
+
+// RejectMethods responds to any request using one of the given methods
+// with a 405 Method Not Allowed before the request reaches the router.
+// If no methods are given, TRACE and CONNECT are rejected.
+func (e *Engine) RejectMethods(methods ...string) {
+	if len(methods) == 0 {
+		methods = []string{TRACE, CONNECT}
+	}
+	if e.rejectMethods == nil {
+		e.rejectMethods = make(map[string]struct{}, len(methods))
+	}
+	for _, m := range methods {
+		e.rejectMethods[strings.ToUpper(m)] = struct{}{}
+	}
+}
+
+// ServeHTTP rejects any disallowed methods and passes all other requests
+// to the RouterGroup.
+func (e *Engine) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if _, ok := e.rejectMethods[r.Method]; ok {
+		w.Header().Set("Allow", strings.Join(e.AllowedMethods(r.URL.Path), ", "))
+		api.Failure(http.StatusMethodNotAllowed, api.Error{
+			Type:    "method_not_allowed",
+			Message: fmt.Sprintf("The %s method is not allowed", r.Method),
+		}).Send(w)
+		return
+	}
+	e.RouterGroup.ServeHTTP(w, r)
 }
 
 // setup is internal kumi middleware. It wraps http.ResponseWriter with
@@ -1 +1,403 @@
 package kumi_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cristiangraz/kumi"
+	"github.com/cristiangraz/kumi/api"
+)
+
+func TestEngine_RejectMethods(t *testing.T) {
+	var ran bool
+	k := kumi.New(&Router{})
+	k.RejectMethods()
+	k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		ran = true
+	})
+
+	r, _ := http.NewRequest("TRACE", "/", nil)
+	w := httptest.NewRecorder()
+	k.ServeHTTP(w, r)
+
+	if ran {
+		t.Fatal("expected handler not to run")
+	} else if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	} else if allow := w.Header().Get("Allow"); allow != "GET, HEAD" {
+		t.Fatalf("unexpected Allow header: %s", allow)
+	} else if w.Body.String() != `{"success":false,"status":405,"code":"method_not_allowed","errors":[{"type":"method_not_allowed","message":"The TRACE method is not allowed"}]}`+"\n" {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+
+	r, _ = http.NewRequest("GET", "/", nil)
+	w = httptest.NewRecorder()
+	k.ServeHTTP(w, r)
+
+	if !ran {
+		t.Fatal("expected handler to run")
+	} else if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	}
+}
+
+func TestEngine_AllowedMethods(t *testing.T) {
+	k := kumi.New(&Router{})
+	k.AutoOptions()
+	k.Post("/users", func(w http.ResponseWriter, r *http.Request) {})
+	k.Get("/users", func(w http.ResponseWriter, r *http.Request) {})
+	k.Delete("/users", func(w http.ResponseWriter, r *http.Request) {})
+
+	expected := []string{"DELETE", "GET", "HEAD", "OPTIONS", "POST"}
+	if given := k.AllowedMethods("/users"); !reflect.DeepEqual(given, expected) {
+		t.Fatalf("TestEngine_AllowedMethods: Expected %v, given %v", expected, given)
+	}
+	if given := k.AllowedMethods("/missing"); len(given) != 0 {
+		t.Fatalf("TestEngine_AllowedMethods: Expected no methods, given %v", given)
+	}
+}
+
+func TestEngine_ServeShutdown(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := &http.Server{}
+	errch := make(chan error, 1)
+	go func() {
+		errch <- kumi.New(&Router{}).Serve(&kumi.ServeConfig{
+			Context: context.Background(),
+			Servers: []kumi.Server{{Server: server, Listener: l}},
+		})
+	}()
+
+	if err := server.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-errch:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected Serve to return")
+	}
+}
+
+func TestEngine_ServeDrained(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errch := make(chan error, 1)
+	go func() {
+		errch <- kumi.New(&Router{}).Serve(&kumi.ServeConfig{
+			Context:          ctx,
+			InterruptTimeout: time.Minute,
+			ContextTimeout:   time.Minute,
+			Servers:          []kumi.Server{{Server: &http.Server{}, Listener: l}},
+		})
+	}()
+
+	// With no active connections Serve should return without waiting
+	// for ContextTimeout.
+	cancel()
+	select {
+	case err := <-errch:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected Serve to return once connections drained")
+	}
+}
+
+// transport is a kumi.Transport that serves until it is shut down.
+type transport struct {
+	closed   chan struct{}
+	shutdown bool
+}
+
+func (t *transport) ListenAndServe() error {
+	<-t.closed
+	return http.ErrServerClosed
+}
+
+func (t *transport) Shutdown(ctx context.Context) error {
+	t.shutdown = true
+	close(t.closed)
+	return nil
+}
+
+func (t *transport) Close() error {
+	close(t.closed)
+	return nil
+}
+
+func TestEngine_ServeTransport(t *testing.T) {
+	tr := &transport{closed: make(chan struct{})}
+	server := &http.Server{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errch := make(chan error, 1)
+	go func() {
+		errch <- kumi.New(&Router{}).Serve(&kumi.ServeConfig{
+			Context:          ctx,
+			InterruptTimeout: time.Second,
+			ContextTimeout:   time.Second,
+			Servers:          []kumi.Server{{Server: server, Transport: tr}},
+		})
+	}()
+
+	cancel()
+	select {
+	case err := <-errch:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		} else if !tr.shutdown {
+			t.Fatal("expected the transport to be shut down")
+		} else if server.Handler == nil {
+			t.Fatal("expected Serve to set the server's handler")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected Serve to return")
+	}
+}
+
+func TestEngine_Draining(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	started, release := make(chan struct{}), make(chan struct{})
+	k := kumi.New(&Router{})
+	k.Health("/healthz", "/readyz", nil)
+	k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errch := make(chan error, 1)
+	go func() {
+		errch <- k.Serve(&kumi.ServeConfig{
+			Context:          ctx,
+			InterruptTimeout: time.Second,
+			ContextTimeout:   5 * time.Second,
+			Servers:          []kumi.Server{{Server: &http.Server{}, Listener: l}},
+		})
+	}()
+
+	go http.Get("http://" + l.Addr().String() + "/")
+	<-started
+	if k.Draining() {
+		t.Fatal("expected Engine not to be draining before shutdown")
+	}
+
+	// Begin a graceful shutdown while the request is in flight.
+	cancel()
+	deadline := time.Now().Add(time.Second)
+	for !k.Draining() {
+		if time.Now().After(deadline) {
+			t.Fatal("expected Engine to be draining")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	r, _ := http.NewRequest("GET", "/readyz", nil)
+	w := httptest.NewRecorder()
+	k.ServeHTTP(w, r)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected readiness to fail while draining, given %d", w.Code)
+	}
+
+	close(release)
+	select {
+	case err := <-errch:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected Serve to return")
+	}
+}
+
+func TestEngine_OnShutdown(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	calls := make(map[string]int)
+	k := kumi.New(&Router{})
+	for _, name := range []string{"metrics", "db"} {
+		name := name
+		k.OnShutdown(func(ctx context.Context) {
+			if _, ok := ctx.Deadline(); !ok {
+				t.Errorf("expected hook %s to receive the shutdown deadline", name)
+			}
+			mu.Lock()
+			calls[name]++
+			mu.Unlock()
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errch := make(chan error, 1)
+	go func() {
+		errch <- k.Serve(&kumi.ServeConfig{
+			Context:          ctx,
+			InterruptTimeout: time.Second,
+			ContextTimeout:   time.Second,
+			Servers:          []kumi.Server{{Server: &http.Server{}, Listener: l}},
+		})
+	}()
+
+	cancel()
+	select {
+	case err := <-errch:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected Serve to return")
+	}
+
+	if calls["metrics"] != 1 || calls["db"] != 1 {
+		t.Fatalf("expected each hook to run once, given %v", calls)
+	}
+}
+
+func TestEngine_OnShutdown_Bounded(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+
+	var mu sync.Mutex
+	var running, maxRunning int
+	k := kumi.New(&Router{})
+	for i := 0; i < 6; i++ {
+		k.OnShutdown(func(ctx context.Context) {
+			// The listener is closed before hooks start.
+			if conn, err := net.Dial("tcp", addr); err == nil {
+				conn.Close()
+				t.Error("expected listener to be closed")
+			}
+
+			mu.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mu.Unlock()
+
+			time.Sleep(10 * time.Millisecond)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+		})
+	}
+	k.OnShutdown(func(ctx context.Context) {
+		panic("flush failed")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errch := make(chan error, 1)
+	go func() {
+		errch <- k.Serve(&kumi.ServeConfig{
+			Context:           ctx,
+			InterruptTimeout:  time.Second,
+			ContextTimeout:    time.Second,
+			ShutdownHookLimit: 2,
+			Servers:           []kumi.Server{{Server: &http.Server{}, Listener: l}},
+		})
+	}()
+
+	cancel()
+	select {
+	case err := <-errch:
+		serr, ok := err.(*kumi.ShutdownError)
+		if !ok || len(serr.Errors) != 1 {
+			t.Fatalf("expected a ShutdownError for the panicking hook, given %v", err)
+		} else if msg := serr.Error(); msg != "shutdown hook 6 panicked: flush failed" {
+			t.Fatalf("unexpected error message: %s", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected Serve to return")
+	}
+
+	if maxRunning != 2 {
+		t.Fatalf("expected at most 2 hooks to run at once, given %d", maxRunning)
+	}
+}
+
+func TestEngine_ServeError(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+
+	err = kumi.New(&Router{}).Serve(&kumi.ServeConfig{
+		Context: context.Background(),
+		Servers: []kumi.Server{{Server: &http.Server{}, Listener: l}},
+	})
+	if err == nil || err == http.ErrServerClosed {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestEngine_Validate(t *testing.T) {
+	if err := kumi.New(&Router{}).Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := kumi.New(nil).Validate(); err == nil {
+		t.Fatal("expected error for nil router")
+	} else if err := kumi.New(nil).Run(":0"); err == nil {
+		t.Fatal("expected Run to return an error for nil router")
+	}
+
+	formatter := api.Formatter
+	defer func() { api.Formatter = formatter }()
+	api.Formatter = nil
+	if err := kumi.New(&Router{}).Validate(); err == nil {
+		t.Fatal("expected error for nil formatter")
+	}
+}
+
+func TestEngine_OnValidate(t *testing.T) {
+	var calls int
+	k := kumi.New(&Router{})
+	k.OnValidate(func() error {
+		calls++
+		return nil
+	})
+	if err := k.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	errSchema := errors.New("invalid schema")
+	k.OnValidate(func() error { return errSchema })
+	if err := k.Validate(); err != errSchema {
+		t.Fatalf("expected %v, given %v", errSchema, err)
+	} else if calls != 2 {
+		t.Fatalf("expected first check to run on each Validate, given %d calls", calls)
+	}
+}
@@ -0,0 +1,21 @@
+package kumi
+
+import (
+	"net/http"
+	"strings"
+)
+
+// SetContentLanguage sets the Content-Language response header to lang and
+// adds Accept-Language to the Vary header, keeping any existing Vary values.
+func SetContentLanguage(w http.ResponseWriter, lang string) {
+	w.Header().Set("Content-Language", lang)
+
+	for _, v := range w.Header()["Vary"] {
+		for _, f := range strings.Split(v, ",") {
+			if f = strings.TrimSpace(f); f == "*" || strings.EqualFold(f, "Accept-Language") {
+				return
+			}
+		}
+	}
+	w.Header().Add("Vary", "Accept-Language")
+}
@@ -0,0 +1,22 @@
+package kumi_test
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/cristiangraz/kumi"
+)
+
+func TestSetContentLanguage(t *testing.T) {
+	w := httptest.NewRecorder()
+	w.Header().Set("Vary", "Origin")
+	kumi.SetContentLanguage(w, "fr-CA")
+	kumi.SetContentLanguage(w, "fr")
+
+	if lang := w.Header().Get("Content-Language"); lang != "fr" {
+		t.Fatalf("unexpected Content-Language: %s", lang)
+	} else if vary := w.Header()["Vary"]; !reflect.DeepEqual(vary, []string{"Origin", "Accept-Language"}) {
+		t.Fatalf("unexpected Vary: %v", vary)
+	}
+}
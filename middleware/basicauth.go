@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/cristiangraz/kumi/api"
+)
+
+// contextKey is the type for keys of values stored in the request context
+// by this package.
+type contextKey struct {
+	name string
+}
+
+// UserContextKey is the request context key holding the username
+// authenticated by BasicAuth.
+var UserContextKey = &contextKey{"user"}
+
+// BasicAuth returns middleware that requires HTTP Basic Authentication.
+// The credentials from the Authorization header are passed to validate.
+// If they are missing or invalid, a 401 Unauthorized api.Failure is sent
+// with a WWW-Authenticate header for realm. Otherwise the username is
+// stored in the request context and can be read with BasicAuthUser.
+func BasicAuth(realm string, validate func(user, pass string) bool) func(http.Handler) http.Handler {
+	challenge := "Basic realm=" + strconv.Quote(realm)
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			user, pass, ok := r.BasicAuth()
+			if !ok || !validate(user, pass) {
+				w.Header().Set("WWW-Authenticate", challenge)
+				api.Failure(http.StatusUnauthorized, api.Error{
+					Type:    "unauthorized",
+					Message: "Invalid or missing credentials",
+				}).Send(w)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), UserContextKey, user)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// BasicAuthUser returns the username authenticated by BasicAuth.
+func BasicAuthUser(r *http.Request) (string, bool) {
+	user, ok := r.Context().Value(UserContextKey).(string)
+	return user, ok
+}
@@ -0,0 +1,60 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cristiangraz/kumi"
+	"github.com/cristiangraz/kumi/middleware"
+	"github.com/cristiangraz/kumi/router"
+)
+
+func TestBasicAuth(t *testing.T) {
+	tests := []struct {
+		user, pass string
+		auth       bool
+		statusCode int
+	}{
+		{user: "admin", pass: "secret", auth: true, statusCode: http.StatusOK},
+		{user: "admin", pass: "wrong", auth: true, statusCode: http.StatusUnauthorized},
+		{statusCode: http.StatusUnauthorized},
+	}
+
+	for i, tt := range tests {
+		var user string
+		k := kumi.New(router.NewHTTPRouter())
+		k.Use(middleware.BasicAuth("api", func(user, pass string) bool {
+			return user == "admin" && pass == "secret"
+		}))
+		k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			user, _ = middleware.BasicAuthUser(r)
+		})
+
+		r, _ := http.NewRequest("GET", "/", nil)
+		if tt.auth {
+			r.SetBasicAuth(tt.user, tt.pass)
+		}
+		w := httptest.NewRecorder()
+		k.ServeHTTP(w, r)
+
+		if w.Code != tt.statusCode {
+			t.Fatalf("TestBasicAuth (%d): Expected status %d, given %d", i, tt.statusCode, w.Code)
+		}
+
+		if tt.statusCode == http.StatusOK {
+			if user != tt.user {
+				t.Fatalf("TestBasicAuth (%d): Expected user %q, given %q", i, tt.user, user)
+			} else if h := w.Header().Get("WWW-Authenticate"); h != "" {
+				t.Fatalf("TestBasicAuth (%d): unexpected WWW-Authenticate header: %s", i, h)
+			}
+			continue
+		}
+
+		if h := w.Header().Get("WWW-Authenticate"); h != `Basic realm="api"` {
+			t.Fatalf("TestBasicAuth (%d): unexpected WWW-Authenticate header: %s", i, h)
+		} else if w.Body.String() != `{"success":false,"status":401,"code":"unauthorized","errors":[{"type":"unauthorized","message":"Invalid or missing credentials"}]}`+"\n" {
+			t.Fatalf("TestBasicAuth (%d): unexpected body: %s", i, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+)
+
+// exceedsBuffer reports whether writing b to buf would exceed max bytes.
+// A max of zero or less means the buffer is uncapped.
+func exceedsBuffer(max int, buf *bytes.Buffer, b []byte) bool {
+	return max > 0 && buf.Len()+len(b) > max
+}
+
+// flushBuffer writes the buffered bytes unmodified to w and resets buf.
+func flushBuffer(w io.Writer, buf *bytes.Buffer) error {
+	_, err := w.Write(buf.Bytes())
+	buf.Reset()
+	return err
+}
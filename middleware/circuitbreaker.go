@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cristiangraz/kumi"
+	"github.com/cristiangraz/kumi/api"
+)
+
+// CircuitBreakerOptions provides settings for CircuitBreaker.
+type CircuitBreakerOptions struct {
+	// Threshold is the number of consecutive failures that opens the
+	// breaker. Defaults to 5.
+	Threshold int
+
+	// Cooldown is how long the breaker stays open before a single probe
+	// request is let through. Defaults to 30 seconds.
+	Cooldown time.Duration
+
+	// IsFailure classifies a response status code as a failure.
+	// Defaults to status codes >= 500.
+	IsFailure func(status int) bool
+}
+
+// Circuit breaker states.
+const (
+	breakerClosed = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// breaker tracks the state of the circuit for a single route.
+type breaker struct {
+	mu       sync.Mutex
+	opt      CircuitBreakerOptions
+	state    int
+	failures int
+	openedAt time.Time
+}
+
+// allow reports whether a request may run. When the breaker is open it
+// also returns how long until the next probe is allowed.
+func (b *breaker) allow() (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if wait := b.opt.Cooldown - time.Since(b.openedAt); wait > 0 {
+			return false, wait
+		}
+		b.state = breakerHalfOpen // Let a single probe through.
+		return true, 0
+	case breakerHalfOpen:
+		return false, b.opt.Cooldown
+	}
+	return true, 0
+}
+
+// record records the result of a request.
+func (b *breaker) record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !failed {
+		b.state = breakerClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.opt.Threshold {
+		b.state = breakerOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// CircuitBreaker returns middleware that tracks failures for each route
+// it wraps. After Threshold consecutive failures the breaker opens and
+// requests are short-circuited with a 503 Service Unavailable without
+// running the handler. After Cooldown a single probe request is allowed;
+// its result closes or reopens the breaker.
+func CircuitBreaker(opt *CircuitBreakerOptions) func(http.Handler) http.Handler {
+	if opt == nil {
+		panic("circuit breaker options required")
+	}
+
+	o := *opt
+	if o.Threshold <= 0 {
+		o.Threshold = 5
+	}
+	if o.Cooldown <= 0 {
+		o.Cooldown = 30 * time.Second
+	}
+	if o.IsFailure == nil {
+		o.IsFailure = func(status int) bool {
+			return status >= http.StatusInternalServerError
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		b := &breaker{opt: o}
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if ok, wait := b.allow(); !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				api.Failure(http.StatusServiceUnavailable, api.Error{
+					Type:    "service_unavailable",
+					Message: "The service is temporarily unavailable",
+				}).Send(w)
+				return
+			}
+
+			failed := true
+			defer func() {
+				b.record(failed)
+			}()
+
+			rw, isKumi := w.(kumi.ResponseWriter)
+			if !isKumi {
+				sw := newResponseWriter(w)
+				rw, w = &sw, &sw
+			}
+			next.ServeHTTP(w, r)
+			failed = o.IsFailure(rw.Status())
+		}
+		return http.HandlerFunc(fn)
+	}
+}
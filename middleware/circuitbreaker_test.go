@@ -0,0 +1,115 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cristiangraz/kumi"
+	"github.com/cristiangraz/kumi/middleware"
+	"github.com/cristiangraz/kumi/router"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var calls int
+	status := http.StatusInternalServerError
+	k := kumi.New(router.NewHTTPRouter())
+	k.Use(middleware.CircuitBreaker(&middleware.CircuitBreakerOptions{
+		Threshold: 3,
+		Cooldown:  20 * time.Millisecond,
+	}))
+	k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(status)
+	})
+	k.Get("/other", func(w http.ResponseWriter, r *http.Request) {})
+
+	request := func(path string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		k.ServeHTTP(w, r)
+		return w
+	}
+
+	// Repeated failures open the breaker.
+	for i := 0; i < 3; i++ {
+		if w := request("/"); w.Code != http.StatusInternalServerError {
+			t.Fatalf("TestCircuitBreaker (%d): Expected status 500, given %d", i, w.Code)
+		}
+	}
+
+	w := request("/")
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	} else if w.Header().Get("Retry-After") != "1" {
+		t.Fatalf("unexpected Retry-After: %s", w.Header().Get("Retry-After"))
+	} else if calls != 3 {
+		t.Fatalf("expected handler not to run, given %d calls", calls)
+	}
+
+	// Other routes have their own breaker.
+	if w := request("/other"); w.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	}
+
+	// After the cooldown a successful probe closes the breaker.
+	time.Sleep(25 * time.Millisecond)
+	status = http.StatusOK
+	if w := request("/"); w.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	} else if w := request("/"); w.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	} else if calls != 5 {
+		t.Fatalf("unexpected calls: %d", calls)
+	}
+}
+
+func TestCircuitBreaker_FailedProbe(t *testing.T) {
+	k := kumi.New(router.NewHTTPRouter())
+	k.Use(middleware.CircuitBreaker(&middleware.CircuitBreakerOptions{
+		Threshold: 1,
+		Cooldown:  20 * time.Millisecond,
+	}))
+	k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	codes := func() int {
+		r, _ := http.NewRequest("GET", "/", nil)
+		w := httptest.NewRecorder()
+		k.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	if c := codes(); c != http.StatusBadGateway {
+		t.Fatalf("unexpected status code: %d", c)
+	} else if c := codes(); c != http.StatusServiceUnavailable {
+		t.Fatalf("unexpected status code: %d", c)
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	if c := codes(); c != http.StatusBadGateway {
+		t.Fatalf("unexpected status code: %d", c)
+	} else if c := codes(); c != http.StatusServiceUnavailable {
+		t.Fatalf("expected breaker to reopen, given %d", c)
+	}
+}
+
+// Ensures failures are recorded when the middleware wraps a handler
+// outside of kumi.
+func TestCircuitBreaker_HTTPHandler(t *testing.T) {
+	h := middleware.CircuitBreaker(&middleware.CircuitBreakerOptions{Threshold: 2})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+
+	expected := []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusServiceUnavailable}
+	for i, status := range expected {
+		r, _ := http.NewRequest("GET", "/", nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != status {
+			t.Fatalf("TestCircuitBreaker_HTTPHandler (%d): Expected status %d, given %d", i, status, w.Code)
+		}
+	}
+}
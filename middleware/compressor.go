@@ -19,7 +19,7 @@ const (
 
 // CompressibleExtensions are the html extensions to compress.
 var (
-	gzipWriterPools = map[int]*sync.Pool{}
+	gzipWriterPools = map[int]*gzipWriterPool{}
 
 	compressibleContentTypes = map[string]struct{}{
 		"text/plain":             {},
@@ -35,34 +35,124 @@ var (
 
 func init() {
 	for _, level := range []int{gzip.NoCompression, gzip.BestSpeed, gzip.BestCompression, gzip.DefaultCompression} {
-		gzipWriterPools[level] = &sync.Pool{
-			New: func() interface{} {
-				w, _ := gzip.NewWriterLevel(nil, level)
-				return w
+		level := level
+		gzipWriterPools[level] = &gzipWriterPool{
+			pool: sync.Pool{
+				New: func() interface{} {
+					w, _ := gzip.NewWriterLevel(nil, level)
+					return w
+				},
 			},
 		}
 	}
 }
 
+// gzipWriterPool reuses gzip writers for one compression level. Writers
+// in the free list are kept across garbage collections; any others are
+// pooled in a sync.Pool, which may drop them.
+type gzipWriterPool struct {
+	pool sync.Pool
+
+	mu   sync.Mutex
+	free []*gzip.Writer
+	size int // capacity of the free list
+}
+
+// Get returns a writer from the free list or the sync.Pool.
+func (p *gzipWriterPool) Get() *gzip.Writer {
+	p.mu.Lock()
+	if n := len(p.free); n > 0 {
+		w := p.free[n-1]
+		p.free = p.free[:n-1]
+		p.mu.Unlock()
+		return w
+	}
+	p.mu.Unlock()
+	return p.pool.Get().(*gzip.Writer)
+}
+
+// Put returns w to the free list if it has room, otherwise to the
+// sync.Pool.
+func (p *gzipWriterPool) Put(w *gzip.Writer) {
+	p.mu.Lock()
+	if len(p.free) < p.size {
+		p.free = append(p.free, w)
+		p.mu.Unlock()
+		return
+	}
+	p.mu.Unlock()
+	p.pool.Put(w)
+}
+
+// WarmCompressor keeps a free list of n gzip writers for each compression
+// level and fills it, so the first n concurrent compressed responses don't
+// need to allocate. Unlike writers in a sync.Pool, the free list survives
+// garbage collection. Call it once at startup.
+func WarmCompressor(n int) {
+	for _, p := range gzipWriterPools {
+		p.mu.Lock()
+		p.size = n
+		for len(p.free) < n {
+			w := p.pool.New().(*gzip.Writer)
+			p.free = append(p.free, w)
+		}
+		p.mu.Unlock()
+	}
+}
+
 // Compressor middleware with default compression.
 // Use CompressorLevel to set a different compression level.
 var Compressor = CompressorLevel(gzip.DefaultCompression)
 
+// CompressorOptions provides settings for the compressor.
+type CompressorOptions struct {
+	// Level is the gzip compression level.
+	Level int
+
+	// MinSizeToCompress is the minimum number of bytes a response must
+	// contain before it is compressed. Responses are buffered until this
+	// size is reached; smaller responses are sent uncompressed.
+	// If zero, all compressible responses are compressed.
+	MinSizeToCompress int
+
+	// NotAcceptable handles requests whose Accept-Encoding excludes both
+	// gzip and identity, i.e. to send an api.Failure(406, ...) error.
+	// If nil, a bare 406 Not Acceptable status is written.
+	NotAcceptable http.Handler
+}
+
 // CompressorLevel returns gzip compressable middleware using a given
 // gzip level.
 func CompressorLevel(level int) func(http.Handler) http.Handler {
-	switch level {
+	return CompressorWithOptions(&CompressorOptions{Level: level})
+}
+
+// CompressorWithOptions returns gzip compressable middleware using
+// the given options.
+func CompressorWithOptions(opt *CompressorOptions) func(http.Handler) http.Handler {
+	if opt == nil {
+		panic("compressor options required")
+	}
+
+	switch opt.Level {
 	case gzip.NoCompression, gzip.BestSpeed, gzip.BestCompression, gzip.DefaultCompression:
 		// OK
 	default:
 		panic("invalid compressor level")
 	}
+	level, minSize := opt.Level, opt.MinSizeToCompress
+	notAcceptable := opt.NotAcceptable
+	if notAcceptable == nil {
+		notAcceptable = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotAcceptable)
+		})
+	}
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// check client's accepted encodings
 			if encs := acceptedEncodings(r); len(encs) == 0 {
-				w.WriteHeader(http.StatusNotAcceptable)
+				notAcceptable.ServeHTTP(w, r)
 				return
 			} else if encs[0] != encGzip {
 				next.ServeHTTP(w, r)
@@ -71,11 +161,12 @@ func CompressorLevel(level int) func(http.Handler) http.Handler {
 
 			// Create a response writer that will defer it's decision to
 			// write gzipped content until the Content-Type header
-			// can be inspected.
+			// can be inspected and enough of the body has been written.
 			gzipWriter := &lazyCompressResponseWriter{
 				ResponseWriter: w,
 				w:              w,
 				level:          level,
+				minSize:        minSize,
 			}
 			defer gzipWriter.Close()
 
@@ -155,21 +246,27 @@ func acceptedEncodings(r *http.Request) []encoding {
 
 type lazyCompressResponseWriter struct {
 	http.ResponseWriter
-	w     io.Writer
-	level int
+	w       io.Writer
+	level   int
+	minSize int
 
-	wroteHeader  bool // whether or not WriteHeader has been called
-	compressable bool // whether or not the response can be compressed
+	code         int    // the status code to write
+	buf          []byte // buffered body while waiting for minSize bytes
+	wroteHeader  bool   // whether or not WriteHeader has been called
+	started      bool   // whether or not the status code has been written
+	compressable bool   // whether or not the response can be compressed
 }
 
 // WriteHeader determines if the compressor should be used and writes
-// the http status code.
+// the http status code. If the response is compressible and a minimum
+// size is set, writing the status code is deferred until enough of the
+// body has been written to make a decision.
 func (w *lazyCompressResponseWriter) WriteHeader(code int) {
 	if w.wroteHeader {
 		return
 	}
 	w.wroteHeader = true
-	defer w.ResponseWriter.WriteHeader(code)
+	w.code = code
 
 	// Use text/plain content-type if one is not provided.
 	if w.Header().Get("Content-Type") == "" {
@@ -183,32 +280,73 @@ func (w *lazyCompressResponseWriter) WriteHeader(code int) {
 	}
 
 	if _, ok := compressibleContentTypes[contentType]; !ok {
+		w.start(false)
 		return
 	} else if strings.Contains(w.Header().Get("Content-Encoding"), "gzip") { // Don't double-encode
+		w.start(false)
 		return
 	}
 
-	// Compressible. Use gzip.Writer.
-	gzw := gzipWriterPools[w.level].Get().(*gzip.Writer)
-	gzw.Reset(w.ResponseWriter)
-	w.w = gzw
+	w.compressable = true
+	if w.minSize == 0 {
+		w.start(true)
+	}
+}
+
+// start writes the status code, switching to the gzip.Writer if compress
+// is true, and flushes any buffered bytes.
+func (w *lazyCompressResponseWriter) start(compress bool) error {
+	w.started = true
 
-	w.Header().Set("Vary", "Accept-Encoding")
-	w.Header().Set("Content-Encoding", "gzip")
-	w.Header().Del("Content-Length")
-	w.Header().Del("Accept-Ranges")
+	if compress {
+		// Compressible. Use gzip.Writer.
+		gzw := gzipWriterPools[w.level].Get()
+		gzw.Reset(w.ResponseWriter)
+		w.w = gzw
+
+		w.Header().Set("Vary", "Accept-Encoding")
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Del("Content-Length")
+		w.Header().Del("Accept-Ranges")
+	}
+	w.ResponseWriter.WriteHeader(w.code)
+
+	if len(w.buf) == 0 {
+		return nil
+	}
+	_, err := w.w.Write(w.buf)
+	w.buf = nil
+	return err
 }
 
 // Write writes to the gzip response writer if the response is compressible.
+// Compressible responses are buffered until the minimum size is reached.
 func (w *lazyCompressResponseWriter) Write(p []byte) (int, error) {
 	if !w.wroteHeader {
 		w.WriteHeader(http.StatusOK)
 	}
-	return w.w.Write(p)
+	if w.started {
+		return w.w.Write(p)
+	}
+
+	w.buf = append(w.buf, p...)
+	if len(w.buf) >= w.minSize {
+		if err := w.start(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
 }
 
-// Close closes the writer.
+// Close closes the writer. A response still being buffered is below
+// the minimum size and is written uncompressed.
 func (w *lazyCompressResponseWriter) Close() error {
+	if w.wroteHeader && !w.started {
+		if err := w.start(false); err != nil {
+			return err
+		}
+	}
+
 	if gzw, ok := w.w.(*gzip.Writer); ok {
 		gzw.Close()
 		gzipWriterPools[w.level].Put(gzw)
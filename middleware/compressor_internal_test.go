@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"runtime"
+	"testing"
+)
+
+func TestWarmCompressor(t *testing.T) {
+	WarmCompressor(3)
+
+	// The free list must survive garbage collection.
+	runtime.GC()
+	runtime.GC()
+
+	for level, p := range gzipWriterPools {
+		newFn := p.pool.New
+		var allocs int
+		p.pool.New = func() interface{} {
+			allocs++
+			return newFn()
+		}
+
+		writers := make([]*gzip.Writer, 3)
+		for i := range writers {
+			writers[i] = p.Get()
+		}
+		for _, w := range writers {
+			p.Put(w)
+		}
+		p.pool.New = newFn
+
+		if allocs != 0 {
+			t.Fatalf("TestWarmCompressor (%d): Expected pre-created writers, given %d allocations", level, allocs)
+		} else if len(p.free) != 3 {
+			t.Fatalf("TestWarmCompressor (%d): Expected writers to return to the free list, given %d", level, len(p.free))
+		}
+	}
+}
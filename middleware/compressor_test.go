@@ -0,0 +1,114 @@
+package middleware_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cristiangraz/kumi"
+	"github.com/cristiangraz/kumi/api"
+	"github.com/cristiangraz/kumi/middleware"
+	"github.com/cristiangraz/kumi/router"
+)
+
+func TestCompressor_MinSizeToCompress(t *testing.T) {
+	tests := []struct {
+		body       string
+		compressed bool
+	}{
+		{body: strings.Repeat("a", 99)},
+		{body: strings.Repeat("a", 100), compressed: true},
+		{body: strings.Repeat("a", 1000), compressed: true},
+	}
+
+	for i, tt := range tests {
+		k := kumi.New(router.NewHTTPRouter())
+		k.Use(middleware.CompressorWithOptions(&middleware.CompressorOptions{
+			Level:             gzip.DefaultCompression,
+			MinSizeToCompress: 100,
+		}))
+		k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+
+			// Write in chunks to verify bytes are buffered before deciding.
+			for _, b := range []byte(tt.body) {
+				w.Write([]byte{b})
+			}
+		})
+
+		r, _ := http.NewRequest("GET", "/", nil)
+		r.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		k.ServeHTTP(w, r)
+
+		if w.Code != http.StatusCreated {
+			t.Errorf("(%d): unexpected status code: %d", i, w.Code)
+		}
+
+		if !tt.compressed {
+			if enc := w.Header().Get("Content-Encoding"); enc != "" {
+				t.Errorf("(%d): unexpected Content-Encoding: %s", i, enc)
+			} else if w.Body.String() != tt.body {
+				t.Errorf("(%d): unexpected body: %s", i, w.Body.String())
+			}
+			continue
+		}
+
+		if enc := w.Header().Get("Content-Encoding"); enc != "gzip" {
+			t.Errorf("(%d): unexpected Content-Encoding: %s", i, enc)
+			continue
+		}
+
+		gzr, err := gzip.NewReader(bytes.NewReader(w.Body.Bytes()))
+		if err != nil {
+			t.Errorf("(%d): unexpected error: %v", i, err)
+			continue
+		}
+		if b, err := ioutil.ReadAll(gzr); err != nil {
+			t.Errorf("(%d): unexpected error: %v", i, err)
+		} else if string(b) != tt.body {
+			t.Errorf("(%d): unexpected body: %s", i, b)
+		}
+	}
+}
+
+func TestCompressor_NotAcceptable(t *testing.T) {
+	tests := []struct {
+		opt  *middleware.CompressorOptions
+		body string
+	}{
+		{opt: &middleware.CompressorOptions{Level: gzip.DefaultCompression}},
+		{
+			opt: &middleware.CompressorOptions{
+				Level: gzip.DefaultCompression,
+				NotAcceptable: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					api.Failure(http.StatusNotAcceptable, api.Error{Type: "not_acceptable", Message: "No acceptable content encoding"}).Send(w)
+				}),
+			},
+			body: `{"success":false,"status":406,"code":"not_acceptable","errors":[{"type":"not_acceptable","message":"No acceptable content encoding"}]}`,
+		},
+	}
+
+	for i, tt := range tests {
+		k := kumi.New(router.NewHTTPRouter())
+		k.Use(middleware.CompressorWithOptions(tt.opt))
+		k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			t.Fatalf("(%d): handler should not run", i)
+		})
+
+		r, _ := http.NewRequest("GET", "/", nil)
+		r.Header.Set("Accept-Encoding", "gzip;q=0, identity;q=0")
+		w := httptest.NewRecorder()
+		k.ServeHTTP(w, r)
+
+		if w.Code != http.StatusNotAcceptable {
+			t.Errorf("(%d): unexpected status code: %d", i, w.Code)
+		} else if given := strings.TrimSpace(w.Body.String()); given != tt.body {
+			t.Errorf("(%d): unexpected body: %s", i, given)
+		}
+	}
+}
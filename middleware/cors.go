@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -31,12 +32,37 @@ type CorsOptions struct {
 	AllowHeaders []string
 }
 
+// Validate checks the options for misconfigurations. Credentials cannot be
+// allowed when any origin is allowed.
+func (opt *CorsOptions) Validate() error {
+	if !opt.AllowCredentials {
+		return nil
+	}
+	for _, ao := range opt.AllowOrigin {
+		if ao == "*" {
+			return errors.New("cors: AllowCredentials cannot be used with a wildcard AllowOrigin")
+		}
+	}
+	return nil
+}
+
 // Cors handles CORS requests by setting the appropriate
-// response headers.
+// response headers. When checker is a *kumi.Engine, the options are
+// checked with CorsOptions.Validate as part of Engine.Validate.
+//
+// The options are copied when the middleware is created and the allowed
+// origins are indexed for lock-free lookups. Changes made to opt after
+// Cors returns, including to its AllowOrigin slice, are not seen by the
+// middleware; to change them, create a new middleware.
 func Cors(checker kumi.RouteChecker, opt *CorsOptions) func(next http.Handler) http.Handler {
 	if opt == nil {
 		panic("CORS options required")
 	}
+	if v, ok := checker.(interface{ OnValidate(func() error) }); ok {
+		v.OnValidate(opt.Validate)
+	}
+
+	policy := newCorsPolicy(opt)
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
 			if r.Method == kumi.OPTIONS { // All OPTIONS requests should set the Allow header.
@@ -54,43 +80,35 @@ func Cors(checker kumi.RouteChecker, opt *CorsOptions) func(next http.Handler) h
 				return
 			}
 
-			var validOrigin bool
-			for _, ao := range opt.AllowOrigin {
-				if ao == "*" {
-					validOrigin = true
-					w.Header().Set("Access-Control-Allow-Origin", origin) // Mirror the origin
-					break
-				} else if ao == origin {
-					validOrigin = true
-					w.Header().Set("Vary", "Origin")
-					w.Header().Set("Access-Control-Allow-Origin", origin)
-					break
-				}
-			}
-
-			// If there is no valid origin match, continue.
-			if !validOrigin {
+			switch policy.match(origin) {
+			case originWildcard:
+				w.Header().Set("Access-Control-Allow-Origin", origin) // Mirror the origin
+			case originExact:
+				w.Header().Set("Vary", "Origin")
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+			default:
+				// If there is no valid origin match, continue.
 				next.ServeHTTP(w, r)
 				return
 			}
 
-			if len(opt.AllowHeaders) > 0 {
-				w.Header().Set("Access-Control-Allow-Headers", strings.Join(opt.AllowHeaders, ", "))
+			if policy.allowHeaders != "" {
+				w.Header().Set("Access-Control-Allow-Headers", policy.allowHeaders)
 			} else if acrh := r.Header.Get("Access-Control-Request-Headers"); acrh != "" {
 				// If no allow headers are set, mirror the request headers
 				w.Header().Set("Access-Control-Allow-Headers", acrh)
 			}
 
-			if len(opt.ExposeHeaders) > 0 {
-				w.Header().Set("Access-Control-Expose-Headers", strings.Join(opt.ExposeHeaders, ", "))
+			if policy.exposeHeaders != "" {
+				w.Header().Set("Access-Control-Expose-Headers", policy.exposeHeaders)
 			}
 
-			if opt.AllowCredentials {
+			if policy.allowCredentials {
 				w.Header().Set("Access-Control-Allow-Credentials", "true")
 			}
 
-			if opt.MaxAge.Seconds() > 0 {
-				w.Header().Set("Access-Control-Max-Age", fmt.Sprintf("%.0f", opt.MaxAge.Seconds()))
+			if policy.maxAge != "" {
+				w.Header().Set("Access-Control-Max-Age", policy.maxAge)
 			}
 
 			// For OPTIONS requests, don't continue to next middleware
@@ -106,6 +124,62 @@ func Cors(checker kumi.RouteChecker, opt *CorsOptions) func(next http.Handler) h
 	}
 }
 
+// originMatch is the result of matching a request Origin against
+// CorsOptions.AllowOrigin.
+type originMatch uint8
+
+const (
+	originDenied originMatch = iota
+	originWildcard
+	originExact
+)
+
+// corsPolicy is a read-only snapshot of CorsOptions with the response
+// header values precomputed.
+type corsPolicy struct {
+	// origins holds the origins listed before any "*" in AllowOrigin.
+	origins  map[string]struct{}
+	wildcard bool
+
+	allowHeaders     string
+	exposeHeaders    string
+	allowCredentials bool
+	maxAge           string
+}
+
+func newCorsPolicy(opt *CorsOptions) *corsPolicy {
+	p := &corsPolicy{
+		origins:          make(map[string]struct{}, len(opt.AllowOrigin)),
+		allowHeaders:     strings.Join(opt.AllowHeaders, ", "),
+		exposeHeaders:    strings.Join(opt.ExposeHeaders, ", "),
+		allowCredentials: opt.AllowCredentials,
+	}
+	if opt.MaxAge.Seconds() > 0 {
+		p.maxAge = fmt.Sprintf("%.0f", opt.MaxAge.Seconds())
+	}
+
+	// AllowOrigin is matched in order, so origins after a "*" are
+	// wildcard matches.
+	for _, ao := range opt.AllowOrigin {
+		if ao == "*" {
+			p.wildcard = true
+			break
+		}
+		p.origins[ao] = struct{}{}
+	}
+	return p
+}
+
+// match matches origin against the allowed origins.
+func (p *corsPolicy) match(origin string) originMatch {
+	if _, ok := p.origins[origin]; ok {
+		return originExact
+	} else if p.wildcard {
+		return originWildcard
+	}
+	return originDenied
+}
+
 func allowedMethods(checker kumi.RouteChecker, req *http.Request) string {
 	methods := make([]string, 0, len(kumi.HTTPMethods))
 	for _, method := range kumi.HTTPMethods {
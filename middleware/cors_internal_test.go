@@ -0,0 +1,167 @@
+package middleware
+
+import (
+	"container/list"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestCorsPolicy_Match(t *testing.T) {
+	tests := []struct {
+		allowOrigin []string
+		origin      string
+		match       originMatch
+	}{
+		{allowOrigin: []string{"http://a.com"}, origin: "http://a.com", match: originExact},
+		{allowOrigin: []string{"http://a.com"}, origin: "http://b.com", match: originDenied},
+		{allowOrigin: []string{"*"}, origin: "http://b.com", match: originWildcard},
+		{allowOrigin: []string{"http://a.com", "*"}, origin: "http://a.com", match: originExact},
+		{allowOrigin: []string{"*", "http://a.com"}, origin: "http://a.com", match: originWildcard},
+		{origin: "http://a.com", match: originDenied},
+	}
+
+	for i, tt := range tests {
+		p := newCorsPolicy(&CorsOptions{AllowOrigin: tt.allowOrigin})
+		if match := p.match(tt.origin); match != tt.match {
+			t.Fatalf("TestCorsPolicy_Match (%d): Expected %d, given %d", i, tt.match, match)
+		}
+	}
+}
+
+// Ensures changes to the options after Cors is created are ignored.
+func TestCorsPolicy_Snapshot(t *testing.T) {
+	opt := &CorsOptions{AllowOrigin: []string{"http://a.com"}, ExposeHeaders: []string{"X-Total"}}
+	h := Cors(allRoutes{}, opt)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	opt.AllowOrigin[0] = "http://b.com"
+	opt.ExposeHeaders[0] = "X-Other"
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	r.Header.Set("Origin", "http://a.com")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if h := w.Header().Get("Access-Control-Allow-Origin"); h != "http://a.com" {
+		t.Fatalf("TestCorsPolicy_Snapshot: unexpected allow origin: %q", h)
+	} else if h := w.Header().Get("Access-Control-Expose-Headers"); h != "X-Total" {
+		t.Fatalf("TestCorsPolicy_Snapshot: unexpected expose headers: %q", h)
+	}
+}
+
+type allRoutes struct{}
+
+func (allRoutes) HasRoute(method string, pattern string) bool { return true }
+
+func BenchmarkCors(b *testing.B) {
+	allowOrigin := make([]string, 1000)
+	for i := range allowOrigin {
+		allowOrigin[i] = fmt.Sprintf("https://%d.example.com", i)
+	}
+
+	h := Cors(allRoutes{}, &CorsOptions{AllowOrigin: allowOrigin})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	requests := make([]*http.Request, 500)
+	for i := range requests {
+		requests[i], _ = http.NewRequest("GET", "/", nil)
+		requests[i].Header.Set("Origin", allowOrigin[len(allowOrigin)-1-i])
+	}
+
+	w := httptest.NewRecorder()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.ServeHTTP(w, requests[i%len(requests)])
+	}
+}
+
+// lruOriginCache is the LRU cache of origin matches Cors used before the
+// allowed origins were indexed into a map. It is kept for
+// BenchmarkCorsOriginMatch.
+type lruOriginCache struct {
+	mu          sync.Mutex
+	size        int
+	ll          *list.List
+	items       map[string]*list.Element
+	allowOrigin []string
+}
+
+type lruOriginCacheEntry struct {
+	origin string
+	match  originMatch
+}
+
+// match returns the cached match for origin, scanning allowOrigin and
+// caching the result on a miss.
+func (c *lruOriginCache) match(origin string) originMatch {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[origin]; ok {
+		c.ll.MoveToFront(e)
+		return e.Value.(*lruOriginCacheEntry).match
+	}
+
+	match := originDenied
+	for _, ao := range c.allowOrigin {
+		if ao == "*" {
+			match = originWildcard
+			break
+		} else if ao == origin {
+			match = originExact
+			break
+		}
+	}
+
+	c.items[origin] = c.ll.PushFront(&lruOriginCacheEntry{origin: origin, match: match})
+	if c.ll.Len() > c.size {
+		e := c.ll.Back()
+		c.ll.Remove(e)
+		delete(c.items, e.Value.(*lruOriginCacheEntry).origin)
+	}
+	return match
+}
+
+// Compares the previous LRU origin cache with the origin map used by
+// corsPolicy. Run with -cpu to see the effect of the LRU's mutex.
+func BenchmarkCorsOriginMatch(b *testing.B) {
+	allowOrigin := make([]string, 1000)
+	for i := range allowOrigin {
+		allowOrigin[i] = fmt.Sprintf("https://%d.example.com", i)
+	}
+	origins := make([]string, 2000) // Half are denied.
+	for i := range origins {
+		origins[i] = fmt.Sprintf("https://%d.example.com", i)
+	}
+
+	matchers := []struct {
+		name  string
+		match func(origin string) originMatch
+	}{
+		{
+			name: "lru",
+			match: (&lruOriginCache{
+				size:        1024,
+				ll:          list.New(),
+				items:       make(map[string]*list.Element, 1024),
+				allowOrigin: allowOrigin,
+			}).match,
+		},
+		{
+			name:  "map",
+			match: newCorsPolicy(&CorsOptions{AllowOrigin: allowOrigin}).match,
+		},
+	}
+
+	for _, m := range matchers {
+		b.Run(m.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				for i := 0; pb.Next(); i++ {
+					m.match(origins[i%len(origins)])
+				}
+			})
+		})
+	}
+}
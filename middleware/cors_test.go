@@ -403,3 +403,58 @@ func MustNewRequest(method, urlStr string, body io.Reader) *http.Request {
 	}
 	return req
 }
+
+func TestCorsOptions_Validate(t *testing.T) {
+	tests := []struct {
+		options *middleware.CorsOptions
+		valid   bool
+	}{
+		{options: &middleware.CorsOptions{AllowOrigin: []string{"*"}}, valid: true},
+		{options: &middleware.CorsOptions{AllowOrigin: []string{"https://example.com"}, AllowCredentials: true}, valid: true},
+		{options: &middleware.CorsOptions{AllowOrigin: []string{"https://example.com", "*"}, AllowCredentials: true}, valid: false},
+	}
+
+	for i, tt := range tests {
+		if err := tt.options.Validate(); (err == nil) != tt.valid {
+			t.Fatalf("TestCorsOptions_Validate (%d): Expected valid %t, given error %v", i, tt.valid, err)
+		}
+	}
+}
+
+// Ensures invalid options are reported by Engine.Validate.
+func TestCors_InvalidOptions(t *testing.T) {
+	k := kumi.New(router.NewHTTPRouter())
+	k.Use(middleware.Cors(k, &middleware.CorsOptions{
+		AllowOrigin:      []string{"*"},
+		AllowCredentials: true,
+	}))
+
+	if err := k.Validate(); err == nil {
+		t.Fatal("TestCors_InvalidOptions: Expected Validate to return an error")
+	}
+}
+
+// Ensures AutoOptions does not interfere with CORS preflight requests.
+func TestCors_AutoOptions(t *testing.T) {
+	rtr := router.NewHTTPRouter()
+	k := kumi.New(rtr)
+	k.AutoOptions()
+	k.Use(middleware.Cors(rtr, &middleware.CorsOptions{
+		AllowOrigin: []string{"http://foo.com"},
+	}))
+	k.Delete("/", func(w http.ResponseWriter, r *http.Request) {})
+
+	// CORS preflight.
+	w := httptest.NewRecorder()
+	r := MustNewRequest("OPTIONS", "/", nil)
+	r.Header.Set("Origin", "http://foo.com")
+	k.ServeHTTP(w, r)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	} else if h := w.Header().Get("Access-Control-Allow-Origin"); h != "http://foo.com" {
+		t.Fatalf("unexpected access control allow origin: %s", h)
+	} else if h := w.Header().Get("Access-Control-Allow-Methods"); h != "OPTIONS, DELETE" {
+		t.Fatalf("unexpected access control allow methods: %s", h)
+	}
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/cristiangraz/kumi"
+	"github.com/cristiangraz/kumi/api"
+)
+
+// formatWriter carries the formatter set by EnforceFormat so that
+// api.Response.Send can use it.
+type formatWriter struct {
+	responseWriter
+	f api.FormatterFn
+}
+
+var _ kumi.ResponseWriter = &formatWriter{}
+
+// Formatter returns the formatter for the request.
+func (w *formatWriter) Formatter() api.FormatterFn {
+	return w.f
+}
+
+// Unwrap returns the wrapped http.ResponseWriter.
+func (w *formatWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Flush implements the http.Flusher interface.
+func (w *formatWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// EnforceFormat returns middleware that makes f the formatter for every
+// api.Response sent by the routes it wraps, regardless of api.Formatter.
+// The formatter is also set on the request context for use with
+// api.RequestFormatter. Middleware that wraps the ResponseWriter after
+// EnforceFormat hides the formatter from Send unless the wrapper has an
+// Unwrap() http.ResponseWriter method, so add EnforceFormat last.
+func EnforceFormat(f api.FormatterFn) func(http.Handler) http.Handler {
+	if f == nil {
+		panic("formatter required")
+	}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			fw := &formatWriter{responseWriter: newResponseWriter(w), f: f}
+			next.ServeHTTP(fw, api.WithFormatter(r, f))
+		}
+		return http.HandlerFunc(fn)
+	}
+}
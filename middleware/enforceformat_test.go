@@ -0,0 +1,57 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/cristiangraz/kumi"
+	"github.com/cristiangraz/kumi/api"
+	"github.com/cristiangraz/kumi/middleware"
+	"github.com/cristiangraz/kumi/router"
+)
+
+func TestEnforceFormat(t *testing.T) {
+	k := kumi.New(router.NewHTTPRouter())
+	k.Get("/json", func(w http.ResponseWriter, r *http.Request) {
+		api.Success(nil).Send(w)
+	})
+
+	g := k.GroupPath("/xml", middleware.EnforceFormat(api.XML))
+	g.Get("/success", func(w http.ResponseWriter, r *http.Request) {
+		api.Success(nil).Send(w)
+	})
+	g.Get("/error", func(w http.ResponseWriter, r *http.Request) {
+		api.Error{StatusCode: http.StatusNotFound, Type: "not_found", Message: "Not found"}.Send(w)
+	})
+	g.Get("/context", func(w http.ResponseWriter, r *http.Request) {
+		if f := api.RequestFormatter(r); reflect.ValueOf(f).Pointer() != reflect.ValueOf(api.FormatterFn(api.XML)).Pointer() {
+			t.Fatal("expected XML formatter in the request context")
+		}
+		api.Success(nil).SendFormat(w, api.RequestFormatter(r))
+	})
+
+	tests := []struct {
+		path        string
+		status      int
+		contentType string
+	}{
+		{path: "/json", status: http.StatusOK, contentType: "application/json"},
+		{path: "/xml/success", status: http.StatusOK, contentType: "application/xml"},
+		{path: "/xml/error", status: http.StatusNotFound, contentType: "application/xml"},
+		{path: "/xml/context", status: http.StatusOK, contentType: "application/xml"},
+	}
+
+	for i, tt := range tests {
+		r, _ := http.NewRequest("GET", tt.path, nil)
+		w := httptest.NewRecorder()
+		k.ServeHTTP(w, r)
+
+		if w.Code != tt.status {
+			t.Errorf("(%d): unexpected status code: %d", i, w.Code)
+		} else if ct := w.Header().Get("Content-Type"); ct != tt.contentType {
+			t.Errorf("(%d): unexpected Content-Type: %s", i, ct)
+		}
+	}
+}
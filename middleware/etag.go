@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/cristiangraz/kumi"
+	"github.com/cristiangraz/kumi/cache"
+)
+
+// etagResponseWriter buffers the response so an ETag can be computed
+// from the body before anything is written.
+type etagResponseWriter struct {
+	responseWriter
+	buf bytes.Buffer
+
+	// max is the buffering cap. Once exceeded the response is streamed
+	// untagged and passthrough is set.
+	max         int
+	passthrough bool
+	flushed     int
+}
+
+var _ kumi.ResponseWriter = &etagResponseWriter{}
+
+var etagResponseWriterPool = &sync.Pool{
+	New: func() interface{} {
+		return &etagResponseWriter{}
+	},
+}
+
+// reset the response writer pulled from the pool
+func (e *etagResponseWriter) reset(w http.ResponseWriter, max int) {
+	e.responseWriter = newResponseWriter(w)
+	e.buf.Reset()
+	e.max = max
+	e.passthrough = false
+	e.flushed = 0
+}
+
+// WriteHeader records the status code without writing it.
+func (e *etagResponseWriter) WriteHeader(s int) {
+	if e.wroteHeader {
+		return
+	}
+	e.wroteHeader = true
+	e.status = s
+}
+
+// Write buffers the response body. If the buffer would exceed the cap,
+// the buffered bytes and b are written to the underlying ResponseWriter
+// and the rest of the response is passed through.
+func (e *etagResponseWriter) Write(b []byte) (int, error) {
+	if !e.wroteHeader {
+		e.WriteHeader(http.StatusOK)
+	}
+
+	if !e.passthrough && exceedsBuffer(e.max, &e.buf, b) {
+		e.passthrough = true
+		e.ResponseWriter.WriteHeader(e.status)
+		e.flushed = e.buf.Len()
+		if err := flushBuffer(e.ResponseWriter, &e.buf); err != nil {
+			return 0, err
+		}
+	}
+
+	if e.passthrough {
+		n, err := e.ResponseWriter.Write(b)
+		e.flushed += n
+		return n, err
+	}
+	return e.buf.Write(b)
+}
+
+// Written returns the number of bytes buffered or passed through.
+func (e *etagResponseWriter) Written() int {
+	return e.flushed + e.buf.Len()
+}
+
+// ETag returns middleware that buffers GET and HEAD responses and sets
+// a strong ETag header computed from the response body. If the handler
+// sets its own ETag, that value is used instead. When the request's
+// If-None-Match header matches the ETag, a 304 Not Modified is sent
+// with no body. Only 200 OK responses are tagged.
+func ETag() func(http.Handler) http.Handler {
+	return ETagWithOptions(&ETagOptions{})
+}
+
+// ETagOptions holds options for the ETag middleware.
+type ETagOptions struct {
+	// MaxBufferBytes caps the number of bytes buffered per response.
+	// Responses larger than the cap are streamed without an ETag.
+	// If zero, responses are buffered in full.
+	MaxBufferBytes int
+}
+
+// ETagWithOptions returns ETag middleware using the given options.
+func ETagWithOptions(opt *ETagOptions) func(http.Handler) http.Handler {
+	if opt == nil {
+		panic("etag options required")
+	}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != kumi.GET && r.Method != kumi.HEAD {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ew := etagResponseWriterPool.Get().(*etagResponseWriter)
+			ew.reset(w, opt.MaxBufferBytes)
+			defer etagResponseWriterPool.Put(ew)
+
+			next.ServeHTTP(ew, r)
+
+			if !ew.wroteHeader || ew.passthrough {
+				return
+			} else if ew.status != http.StatusOK {
+				w.WriteHeader(ew.status)
+				w.Write(ew.buf.Bytes())
+				return
+			}
+
+			etag := w.Header().Get("ETag")
+			if etag == "" {
+				etag = cache.ComputeETag(ew.buf.Bytes(), false)
+				w.Header().Set("ETag", etag)
+			}
+
+			if etagMatch(r.Header.Get("If-None-Match"), etag) {
+				w.Header().Del("Content-Length")
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+
+			w.WriteHeader(http.StatusOK)
+			w.Write(ew.buf.Bytes())
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// etagMatch reports whether the If-None-Match header matches etag using
+// the weak comparison function.
+func etagMatch(header string, etag string) bool {
+	if header == "" {
+		return false
+	}
+
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimSpace(v)
+		if v == "*" || strings.TrimPrefix(v, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cristiangraz/kumi"
+	"github.com/cristiangraz/kumi/middleware"
+	"github.com/cristiangraz/kumi/router"
+)
+
+func TestETag(t *testing.T) {
+	var written int
+	k := kumi.New(router.NewHTTPRouter())
+	k.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r)
+			written = w.(kumi.ResponseWriter).Written()
+		})
+	})
+	k.Use(middleware.ETag())
+	k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello world"))
+	})
+
+	// First request. No cache.
+	r, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	k.ServeHTTP(w, r)
+
+	// sha1("hello world")
+	etag := `"2aae6c35c94fcfb415dbe95f408b9ce91ee846ed"`
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	} else if w.Header().Get("ETag") != etag {
+		t.Fatalf("unexpected etag: %s", w.Header().Get("ETag"))
+	} else if w.Body.String() != "hello world" {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	} else if written != 11 {
+		t.Fatalf("unexpected written value: %d", written)
+	}
+
+	// Second request. Cache hit.
+	r, _ = http.NewRequest("GET", "/", nil)
+	r.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	k.ServeHTTP(w, r)
+
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	} else if w.Header().Get("ETag") != etag {
+		t.Fatalf("unexpected etag: %s", w.Header().Get("ETag"))
+	} else if w.Body.Len() != 0 {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	} else if written != 0 {
+		t.Fatalf("unexpected written value: %d", written)
+	}
+}
+
+func TestETag_IgnoresErrors(t *testing.T) {
+	k := kumi.New(router.NewHTTPRouter())
+	k.Use(middleware.ETag())
+	k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("not found"))
+	})
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	k.ServeHTTP(w, r)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	} else if w.Header().Get("ETag") != "" {
+		t.Fatalf("unexpected etag: %s", w.Header().Get("ETag"))
+	} else if w.Body.String() != "not found" {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+}
+
+func TestETag_MaxBufferBytes(t *testing.T) {
+	k := kumi.New(router.NewHTTPRouter())
+	k.Use(middleware.ETagWithOptions(&middleware.ETagOptions{MaxBufferBytes: 11}))
+	k.Get("/small", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello "))
+		w.Write([]byte("world"))
+	})
+	k.Get("/large", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello "))
+		w.Write([]byte("world!"))
+	})
+
+	// Under the cap: tagged.
+	r, _ := http.NewRequest("GET", "/small", nil)
+	w := httptest.NewRecorder()
+	k.ServeHTTP(w, r)
+
+	if w.Header().Get("ETag") != `"2aae6c35c94fcfb415dbe95f408b9ce91ee846ed"` {
+		t.Fatalf("unexpected etag: %s", w.Header().Get("ETag"))
+	} else if w.Body.String() != "hello world" {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+
+	// Over the cap: passed through untagged.
+	r, _ = http.NewRequest("GET", "/large", nil)
+	w = httptest.NewRecorder()
+	k.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	} else if w.Header().Get("ETag") != "" {
+		t.Fatalf("unexpected etag: %s", w.Header().Get("ETag"))
+	} else if w.Body.String() != "hello world!" {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/cristiangraz/kumi"
+)
+
+// GeoIP returns middleware that resolves the client IP to a GeoInfo with
+// lookup and stores it in the request context, accessible with kumi.Geo.
+// The client IP is taken from r.RemoteAddr. Forwarding headers are not
+// trusted; behind a proxy, add GeoIP after RealIP so RemoteAddr holds the
+// client IP.
+func GeoIP(lookup func(ip string) kumi.GeoInfo) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if ip := remoteIP(r); ip != "" {
+				r = kumi.SetGeo(r, lookup(ip))
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
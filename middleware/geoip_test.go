@@ -0,0 +1,81 @@
+package middleware_test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cristiangraz/kumi"
+	"github.com/cristiangraz/kumi/middleware"
+	"github.com/cristiangraz/kumi/router"
+)
+
+func TestGeoIP(t *testing.T) {
+	countries := map[string]kumi.GeoInfo{
+		"203.0.113.9":  {Country: "NZ", Region: "AUK"},
+		"198.51.100.7": {Country: "US", Region: "CA"},
+	}
+
+	tests := []struct {
+		remoteAddr string
+		headers    map[string]string
+		expected   kumi.GeoInfo
+	}{
+		{remoteAddr: "203.0.113.9:1234", expected: kumi.GeoInfo{Country: "NZ", Region: "AUK"}},
+		{remoteAddr: "203.0.113.9:1234", headers: map[string]string{"X-Forwarded-For": "198.51.100.7"}, expected: kumi.GeoInfo{Country: "NZ", Region: "AUK"}},
+		{remoteAddr: "203.0.113.9:1234", headers: map[string]string{"X-Real-IP": "198.51.100.7"}, expected: kumi.GeoInfo{Country: "NZ", Region: "AUK"}},
+		{remoteAddr: "10.0.0.1:1234"},
+	}
+
+	for i, tt := range tests {
+		var given kumi.GeoInfo
+		var ok bool
+		k := kumi.New(router.NewHTTPRouter())
+		k.Use(middleware.GeoIP(func(ip string) kumi.GeoInfo {
+			return countries[ip]
+		}))
+		k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			given, ok = kumi.Geo(r)
+		})
+
+		r, _ := http.NewRequest("GET", "/", nil)
+		r.RemoteAddr = tt.remoteAddr
+		for k, v := range tt.headers {
+			r.Header.Set(k, v)
+		}
+		k.ServeHTTP(httptest.NewRecorder(), r)
+
+		if !ok {
+			t.Fatalf("TestGeoIP (%d): Expected GeoInfo to be set", i)
+		} else if given != tt.expected {
+			t.Fatalf("TestGeoIP (%d): Expected %#v, given %#v", i, tt.expected, given)
+		}
+	}
+}
+
+func TestGeoIP_RealIP(t *testing.T) {
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+
+	var given kumi.GeoInfo
+	k := kumi.New(router.NewHTTPRouter())
+	k.Use(middleware.RealIP([]net.IPNet{*proxies}))
+	k.Use(middleware.GeoIP(func(ip string) kumi.GeoInfo {
+		if ip == "198.51.100.7" {
+			return kumi.GeoInfo{Country: "US", Region: "CA"}
+		}
+		return kumi.GeoInfo{}
+	}))
+	k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		given, _ = kumi.Geo(r)
+	})
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	r.Header.Set("X-Forwarded-For", "198.51.100.7, 10.0.0.2")
+	k.ServeHTTP(httptest.NewRecorder(), r)
+
+	if expected := (kumi.GeoInfo{Country: "US", Region: "CA"}); given != expected {
+		t.Fatalf("TestGeoIP_RealIP: Expected %#v, given %#v", expected, given)
+	}
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/cristiangraz/kumi"
+	"github.com/cristiangraz/kumi/cache"
+)
+
+// lastModifiedResponseWriter swaps a 200 OK for a 304 Not Modified when
+// the Last-Modified header shows the client's copy is fresh.
+type lastModifiedResponseWriter struct {
+	responseWriter
+	r           *http.Request
+	notModified bool
+}
+
+var _ kumi.ResponseWriter = &lastModifiedResponseWriter{}
+
+// checkNotModified checks the Last-Modified header against the request
+// before the status code is written.
+func (w *lastModifiedResponseWriter) checkNotModified(s int) int {
+	if s == http.StatusOK {
+		if lm, err := http.ParseTime(w.Header().Get("Last-Modified")); err == nil && cache.CheckNotModified(w.r, lm) {
+			w.notModified = true
+			w.Header().Del("Content-Length")
+			return http.StatusNotModified
+		}
+	}
+	return s
+}
+
+// Write discards the body once a 304 Not Modified has been sent.
+func (w *lastModifiedResponseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.notModified {
+		return len(p), nil
+	}
+	return w.responseWriter.Write(p)
+}
+
+// LastModified responds with a 304 Not Modified when the handler sets a
+// Last-Modified header and the request's If-Modified-Since header is not
+// older than it.
+func LastModified(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != kumi.GET && r.Method != kumi.HEAD {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		lw := &lastModifiedResponseWriter{responseWriter: newResponseWriter(w), r: r}
+		lw.header = lw.checkNotModified
+		next.ServeHTTP(lw, r)
+	}
+	return http.HandlerFunc(fn)
+}
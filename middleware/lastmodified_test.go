@@ -0,0 +1,46 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cristiangraz/kumi"
+	"github.com/cristiangraz/kumi/middleware"
+	"github.com/cristiangraz/kumi/router"
+)
+
+func TestLastModified(t *testing.T) {
+	tests := []struct {
+		ifModifiedSince string
+		statusCode      int
+		body            string
+	}{
+		{statusCode: http.StatusOK, body: "content"},
+		{ifModifiedSince: "Mon, 02 Jan 2017 15:04:05 GMT", statusCode: http.StatusNotModified},
+		{ifModifiedSince: "Mon, 02 Jan 2017 15:04:04 GMT", statusCode: http.StatusOK, body: "content"},
+		{ifModifiedSince: "Mon, 02 Jan 2017 15:04:06 GMT", statusCode: http.StatusNotModified},
+	}
+
+	for i, tt := range tests {
+		k := kumi.New(router.NewHTTPRouter())
+		k.Use(middleware.LastModified)
+		k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Last-Modified", "Mon, 02 Jan 2017 15:04:05 GMT")
+			w.Write([]byte("content"))
+		})
+
+		r, _ := http.NewRequest("GET", "/", nil)
+		if tt.ifModifiedSince != "" {
+			r.Header.Set("If-Modified-Since", tt.ifModifiedSince)
+		}
+		w := httptest.NewRecorder()
+		k.ServeHTTP(w, r)
+
+		if w.Code != tt.statusCode {
+			t.Errorf("(%d): unexpected status code: %d", i, w.Code)
+		} else if w.Body.String() != tt.body {
+			t.Errorf("(%d): unexpected body: %s", i, w.Body.String())
+		}
+	}
+}
@@ -1,11 +1,13 @@
 package middleware
 
 import (
+	"io"
 	"net/http"
 	"os"
 	"time"
 
 	"github.com/apex/log"
+	"github.com/apex/log/handlers/logfmt"
 	"github.com/apex/log/handlers/text"
 	"github.com/cristiangraz/kumi"
 )
@@ -45,3 +47,52 @@ func Logger(next http.Handler) http.Handler {
 	}
 	return http.HandlerFunc(fn)
 }
+
+// LoggerWithWriter returns middleware that writes a single logfmt line
+// per request to w with the method, path, status, bytes written, and
+// duration. Unlike Logger, requests served through a plain
+// http.ResponseWriter are logged without the status and bytes written.
+func LoggerWithWriter(w io.Writer) func(http.Handler) http.Handler {
+	l := &log.Logger{
+		Handler: logfmt.New(w),
+		Level:   log.InfoLevel,
+	}
+	return func(next http.Handler) http.Handler {
+		return logRequests(l, next)
+	}
+}
+
+// logRequests logs each request to l. The status and bytes written are
+// only logged if w is a kumi.ResponseWriter.
+func logRequests(l *log.Logger, next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		defer func() {
+			fields := log.Fields{
+				"path":     r.URL.Path,
+				"method":   r.Method,
+				"duration": time.Since(start),
+			}
+
+			rw, ok := w.(kumi.ResponseWriter)
+			if !ok {
+				log.NewEntry(l).WithFields(fields).Info("")
+				return
+			}
+
+			fields["status"] = rw.Status()
+			fields["bytes"] = rw.Written()
+			entry := log.NewEntry(l).WithFields(fields)
+
+			switch {
+			case rw.Status() >= 400:
+				entry.Warn("")
+			default:
+				entry.Info("")
+			}
+		}()
+
+		next.ServeHTTP(w, r)
+	}
+	return http.HandlerFunc(fn)
+}
@@ -0,0 +1,55 @@
+package middleware_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cristiangraz/kumi"
+	"github.com/cristiangraz/kumi/middleware"
+	"github.com/cristiangraz/kumi/router"
+)
+
+func TestLoggerWithWriter(t *testing.T) {
+	var buf bytes.Buffer
+	k := kumi.New(router.NewHTTPRouter())
+	k.Use(middleware.LoggerWithWriter(&buf))
+	k.Get("/users", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("hello world"))
+	})
+
+	r, _ := http.NewRequest("GET", "/users", nil)
+	w := httptest.NewRecorder()
+	k.ServeHTTP(w, r)
+
+	line := buf.String()
+	if strings.Count(line, "\n") != 1 {
+		t.Fatalf("expected a single line, given %q", line)
+	}
+	for _, s := range []string{"method=GET", "path=/users", "status=201", "bytes=11", "duration="} {
+		if !strings.Contains(line, s) {
+			t.Fatalf("expected %q in %q", s, line)
+		}
+	}
+}
+
+func TestLoggerWithWriter_PlainResponseWriter(t *testing.T) {
+	var buf bytes.Buffer
+	h := middleware.LoggerWithWriter(&buf)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello world"))
+	}))
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	line := buf.String()
+	if !strings.Contains(line, "method=GET") {
+		t.Fatalf("unexpected log line: %q", line)
+	} else if strings.Contains(line, "status=") {
+		t.Fatalf("unexpected status in log line: %q", line)
+	}
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/cristiangraz/kumi/api"
+)
+
+// MaxURLLength rejects requests whose path and query string exceed n
+// bytes with a 414 Request-URI Too Long.
+func MaxURLLength(n int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if len(r.URL.RequestURI()) > n {
+				api.Failure(http.StatusRequestURITooLong, api.Error{
+					Type:    "uri_too_long",
+					Message: "The request URI is too long",
+				}).Send(w)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
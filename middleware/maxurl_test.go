@@ -0,0 +1,49 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cristiangraz/kumi"
+	"github.com/cristiangraz/kumi/middleware"
+	"github.com/cristiangraz/kumi/router"
+)
+
+func TestMaxURLLength(t *testing.T) {
+	tests := []struct {
+		url        string
+		statusCode int
+		ran        bool
+	}{
+		{
+			url:        "/?q=" + strings.Repeat("a", 28),
+			statusCode: http.StatusOK,
+			ran:        true,
+		},
+		{
+			url:        "/?q=" + strings.Repeat("a", 29),
+			statusCode: http.StatusRequestURITooLong,
+		},
+	}
+
+	for i, tt := range tests {
+		var ran bool
+		k := kumi.New(router.NewHTTPRouter())
+		k.Use(middleware.MaxURLLength(32))
+		k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			ran = true
+		})
+
+		r, _ := http.NewRequest("GET", tt.url, nil)
+		w := httptest.NewRecorder()
+		k.ServeHTTP(w, r)
+
+		if w.Code != tt.statusCode {
+			t.Errorf("(%d): unexpected status code: %d", i, w.Code)
+		} else if ran != tt.ran {
+			t.Errorf("(%d): expected handler ran to be %v", i, tt.ran)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cristiangraz/kumi"
+)
+
+// MethodOverrideHeader is the request header read by MethodOverride.
+const MethodOverrideHeader = "X-HTTP-Method-Override"
+
+// maxMethodOverrideBody is the largest form body MethodOverride reads
+// when looking for the _method field.
+const maxMethodOverrideBody = 10 << 20
+
+// MethodOverride returns middleware that rewrites the method of POST
+// requests from the X-HTTP-Method-Override header or, for
+// application/x-www-form-urlencoded bodies, the _method form field. Only
+// PUT, PATCH and DELETE are accepted; a POST is never turned into a safe
+// method. The form body is buffered and r.Body restored so downstream
+// handlers can still read it. Bodies larger than 10MB are not checked
+// for the _method field. Because
+// middleware added with Use only runs on matched routes, wrap the Engine
+// with this middleware.
+func MethodOverride() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == kumi.POST {
+				method := r.Header.Get(MethodOverrideHeader)
+				if method == "" {
+					method = formMethod(r)
+				}
+				if m, ok := overrideMethod(method); ok {
+					r.Method = m
+				}
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// formMethod returns the _method field from a urlencoded form body
+// without consuming r.Body.
+func formMethod(r *http.Request) string {
+	mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mt != "application/x-www-form-urlencoded" || r.ContentLength > maxMethodOverrideBody {
+		return ""
+	}
+
+	// BufferBody restores r.Body if an unknown length body is too large.
+	b, err := kumi.BufferBody(r, maxMethodOverrideBody)
+	if err != nil {
+		return ""
+	}
+	form, err := url.ParseQuery(string(b))
+	if err != nil {
+		return ""
+	}
+	return form.Get("_method")
+}
+
+// overrideMethod returns the canonical method if it is one POST may be
+// overridden with.
+func overrideMethod(method string) (string, bool) {
+	switch method = strings.ToUpper(strings.TrimSpace(method)); method {
+	case kumi.PUT, kumi.PATCH, kumi.DELETE:
+		return method, true
+	}
+	return "", false
+}
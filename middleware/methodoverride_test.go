@@ -0,0 +1,72 @@
+package middleware_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cristiangraz/kumi"
+	"github.com/cristiangraz/kumi/middleware"
+	"github.com/cristiangraz/kumi/router"
+)
+
+func TestMethodOverride(t *testing.T) {
+	tests := []struct {
+		method      string
+		header      string
+		contentType string
+		body        string
+		chunked     bool
+		want        string
+	}{
+		{method: "POST", header: "PUT", want: "PUT"},
+		{method: "POST", header: "delete", want: "DELETE"},
+		{method: "POST", contentType: "application/x-www-form-urlencoded", body: "_method=PATCH&name=kumi", want: "PATCH"},
+		{method: "POST", contentType: "application/x-www-form-urlencoded; charset=utf-8", body: "name=kumi&_method=delete", want: "DELETE"},
+		{method: "POST", header: "PUT", contentType: "application/x-www-form-urlencoded", body: "_method=DELETE", want: "PUT"},
+		{method: "POST", header: "TRACE", want: "POST"},
+		{method: "POST", header: "GET", want: "POST"},
+		{method: "POST", header: "head", want: "POST"},
+		{method: "POST", contentType: "application/x-www-form-urlencoded", body: "_method=OPTIONS", want: "POST"},
+		{method: "POST", contentType: "application/x-www-form-urlencoded", body: "_method=CONNECT", want: "POST"},
+		{method: "POST", contentType: "application/json", body: `{"_method": "PUT"}`, want: "POST"},
+		{method: "GET", header: "DELETE", want: "GET"},
+		{method: "POST", want: "POST"},
+		{method: "POST", contentType: "application/x-www-form-urlencoded", body: "_method=DELETE&" + strings.Repeat("a", 10<<20), chunked: true, want: "POST"},
+	}
+
+	for i, tt := range tests {
+		var method, body string
+		k := kumi.New(router.NewHTTPRouter())
+		h := func(w http.ResponseWriter, r *http.Request) {
+			method = r.Method
+			b, _ := ioutil.ReadAll(r.Body)
+			body = string(b)
+		}
+		for _, m := range []string{"GET", "POST", "PUT", "PATCH", "DELETE"} {
+			k.Register([]kumi.Route{{Method: m, Pattern: "/users", Handler: h}})
+		}
+
+		r, _ := http.NewRequest(tt.method, "/users", strings.NewReader(tt.body))
+		if tt.chunked {
+			// An unknown length body, i.e. Transfer-Encoding: chunked.
+			r.Body = ioutil.NopCloser(strings.NewReader(tt.body))
+			r.ContentLength = -1
+		}
+		if tt.header != "" {
+			r.Header.Set(middleware.MethodOverrideHeader, tt.header)
+		}
+		if tt.contentType != "" {
+			r.Header.Set("Content-Type", tt.contentType)
+		}
+		middleware.MethodOverride()(k).ServeHTTP(httptest.NewRecorder(), r)
+
+		if method != tt.want {
+			t.Errorf("TestMethodOverride (%d): Expected method %s, given %s", i, tt.want, method)
+		} else if body != tt.body {
+			t.Errorf("TestMethodOverride (%d): Expected body of %d bytes, given %d bytes", i, len(tt.body), len(body))
+		}
+	}
+}
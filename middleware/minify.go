@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"bytes"
 	"io"
 	"log"
 	"mime"
@@ -24,6 +25,12 @@ type (
 		minifier    *minify.M
 		allowed     map[string]struct{}
 		initialized bool
+
+		// When max is set, the response is buffered in buf and minified
+		// on close. mediaType is cleared if the cap is exceeded.
+		max       int
+		buf       bytes.Buffer
+		mediaType string
 	}
 )
 
@@ -36,12 +43,15 @@ var (
 )
 
 // reset the response writer pulled from the pool
-func (m *minifyResponseWriter) reset(w http.ResponseWriter, minifier *minify.M, allowed map[string]struct{}) {
+func (m *minifyResponseWriter) reset(w http.ResponseWriter, minifier *minify.M, allowed map[string]struct{}, max int) {
 	m.ResponseWriter = w
 	m.minifier = minifier
 	m.allowed = allowed
 	m.WriteCloser = nil
 	m.initialized = false
+	m.max = max
+	m.buf.Reset()
+	m.mediaType = ""
 }
 
 // Write defers to the initializer on first run to
@@ -51,11 +61,21 @@ func (m *minifyResponseWriter) Write(b []byte) (int, error) {
 		m.initialize()
 	}
 
-	if m.WriteCloser == nil {
+	if m.WriteCloser != nil {
+		return m.WriteCloser.Write(b)
+	} else if m.mediaType == "" {
 		return m.ResponseWriter.Write(b)
 	}
 
-	return m.WriteCloser.Write(b)
+	// Buffering. Pass the response through unminified if it exceeds the cap.
+	if exceedsBuffer(m.max, &m.buf, b) {
+		m.mediaType = ""
+		if err := flushBuffer(m.ResponseWriter, &m.buf); err != nil {
+			return 0, err
+		}
+		return m.ResponseWriter.Write(b)
+	}
+	return m.buf.Write(b)
 }
 
 // initialize checks for a valid content-type in the allowed list of
@@ -80,11 +100,20 @@ func (m *minifyResponseWriter) initialize() {
 		return
 	}
 
+	if m.max > 0 {
+		m.mediaType = ct
+		return
+	}
 	m.WriteCloser = m.minifier.Writer(ct, m.ResponseWriter)
 }
 
 // closes the minifier.
 func (m *minifyResponseWriter) close() {
+	if m.mediaType != "" && m.buf.Len() > 0 {
+		m.WriteCloser = m.minifier.Writer(m.mediaType, m.ResponseWriter)
+		m.WriteCloser.Write(m.buf.Bytes())
+	}
+
 	if m.WriteCloser == nil {
 		return
 	}
@@ -99,8 +128,28 @@ var Minify = MinifyTypes("text/css", "text/javascript", "application/json", "tex
 
 // MinifyTypes returns a custom minifier.
 func MinifyTypes(contentTypes ...string) func(http.Handler) http.Handler {
-	allowed := make(map[string]struct{}, len(contentTypes))
-	for _, t := range contentTypes {
+	return MinifyWithOptions(&MinifyOptions{ContentTypes: contentTypes})
+}
+
+// MinifyOptions holds options for the Minify middleware.
+type MinifyOptions struct {
+	// ContentTypes are the media types to minify.
+	ContentTypes []string
+
+	// MaxBufferBytes caps the number of bytes buffered per response.
+	// Responses larger than the cap are passed through unminified.
+	// If zero, responses are minified as they are written.
+	MaxBufferBytes int
+}
+
+// MinifyWithOptions returns a custom minifier using the given options.
+func MinifyWithOptions(opt *MinifyOptions) func(http.Handler) http.Handler {
+	if opt == nil {
+		panic("minify options required")
+	}
+
+	allowed := make(map[string]struct{}, len(opt.ContentTypes))
+	for _, t := range opt.ContentTypes {
 		allowed[t] = struct{}{}
 	}
 
@@ -114,7 +163,7 @@ func MinifyTypes(contentTypes ...string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
 			mrw := minifyResponseWriterPool.Get().(*minifyResponseWriter)
-			mrw.reset(w, m, allowed)
+			mrw.reset(w, m, allowed, opt.MaxBufferBytes)
 
 			defer func() {
 				mrw.close()
@@ -0,0 +1,46 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cristiangraz/kumi"
+	"github.com/cristiangraz/kumi/middleware"
+	"github.com/cristiangraz/kumi/router"
+)
+
+func TestMinify_MaxBufferBytes(t *testing.T) {
+	k := kumi.New(router.NewHTTPRouter())
+	k.Use(middleware.MinifyWithOptions(&middleware.MinifyOptions{
+		ContentTypes:   []string{"application/json"},
+		MaxBufferBytes: 20,
+	}))
+	k.Get("/small", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name": "Jon"}`))
+	})
+	k.Get("/large", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name": "Jon",`))
+		w.Write([]byte(` "last_name": "Doe"}`))
+	})
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/small", want: `{"name":"Jon"}`},
+		{path: "/large", want: `{"name": "Jon", "last_name": "Doe"}`},
+	}
+
+	for i, tt := range tests {
+		r, _ := http.NewRequest("GET", tt.path, nil)
+		w := httptest.NewRecorder()
+		k.ServeHTTP(w, r)
+
+		if w.Body.String() != tt.want {
+			t.Errorf("TestMinify_MaxBufferBytes (%d): Expected %s, given %s", i, tt.want, w.Body.String())
+		}
+	}
+}
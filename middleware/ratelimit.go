@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cristiangraz/kumi/api"
+	"golang.org/x/time/rate"
+)
+
+// RateLimitOptions provides settings for RateLimit.
+type RateLimitOptions struct {
+	// RPS is the number of requests per second allowed for each client.
+	RPS float64
+
+	// Burst is the maximum number of requests a client can make at once.
+	Burst int
+
+	// KeyFunc returns the key identifying the client. Defaults to the IP
+	// from r.RemoteAddr. Set this when running behind a proxy to key on
+	// X-Forwarded-For instead.
+	KeyFunc func(r *http.Request) string
+
+	// Store tracks each client's requests. Defaults to an in-memory store
+	// using RPS and Burst, which limits each instance separately. Use a
+	// shared store to enforce the limit across instances.
+	Store RateLimitStore
+}
+
+// RateLimitStore tracks request rates per client. Implementations must be
+// safe for concurrent use.
+type RateLimitStore interface {
+	// Allow reports whether a request from the client identified by key
+	// is allowed, consuming from the client's limit if it is. When the
+	// request is not allowed, retryAfter is how long the client should
+	// wait before trying again. If err is non-nil the request is allowed
+	// so that an unavailable store does not take down the service.
+	Allow(ctx context.Context, key string) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// rateLimitIdle is how long a client's bucket is kept without requests.
+const rateLimitIdle = 3 * time.Minute
+
+// clientLimiter holds a client's token bucket.
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// memoryRateLimitStore keeps a token bucket per client in memory.
+type memoryRateLimitStore struct {
+	rps   float64
+	burst int
+
+	mu        sync.Mutex
+	lastSweep time.Time
+	clients   map[string]*clientLimiter
+}
+
+// NewMemoryRateLimitStore returns a RateLimitStore that keeps a token
+// bucket per client in memory, allowing rps requests per second with
+// bursts of up to burst requests. Idle clients are removed periodically.
+func NewMemoryRateLimitStore(rps float64, burst int) RateLimitStore {
+	return &memoryRateLimitStore{
+		rps:     rps,
+		burst:   burst,
+		clients: make(map[string]*clientLimiter),
+	}
+}
+
+// Allow reserves a token from the client's bucket.
+func (s *memoryRateLimitStore) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	res := s.limiter(key).Reserve()
+	if !res.OK() {
+		return false, time.Second, nil
+	} else if d := res.Delay(); d > 0 {
+		res.Cancel()
+		return false, d, nil
+	}
+	return true, 0, nil
+}
+
+// limiter returns the client's token bucket, sweeping idle clients at
+// most once a minute.
+func (s *memoryRateLimitStore) limiter(key string) *rate.Limiter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastSweep) > time.Minute {
+		for k, c := range s.clients {
+			if now.Sub(c.lastSeen) > rateLimitIdle {
+				delete(s.clients, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	c, ok := s.clients[key]
+	if !ok {
+		c = &clientLimiter{limiter: rate.NewLimiter(rate.Limit(s.rps), s.burst)}
+		s.clients[key] = c
+	}
+	c.lastSeen = now
+	return c.limiter
+}
+
+// RateLimit limits each client IP to rps requests per second with bursts
+// of up to burst requests.
+func RateLimit(rps float64, burst int) func(http.Handler) http.Handler {
+	return RateLimitWithOptions(&RateLimitOptions{RPS: rps, Burst: burst})
+}
+
+// RateLimitWithOptions checks each request against the store and responds
+// with 429 Too Many Requests and a Retry-After header once a client has
+// exceeded its limit.
+func RateLimitWithOptions(opt *RateLimitOptions) func(http.Handler) http.Handler {
+	if opt == nil {
+		panic("rate limit options required")
+	}
+
+	keyFn := opt.KeyFunc
+	if keyFn == nil {
+		keyFn = remoteIP
+	}
+	store := opt.Store
+	if store == nil {
+		store = NewMemoryRateLimitStore(opt.RPS, opt.Burst)
+	}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			allowed, retryAfter, err := store.Allow(r.Context(), keyFn(r))
+			if err == nil && !allowed {
+				retry := int(math.Ceil(retryAfter.Seconds()))
+				if retry < 1 {
+					retry = 1
+				}
+
+				w.Header().Set("Retry-After", strconv.Itoa(retry))
+				api.Failure(http.StatusTooManyRequests, api.Error{
+					Type:    "rate_limit_exceeded",
+					Message: "Too many requests",
+				}).Send(w)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// remoteIP returns the IP from r.RemoteAddr.
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,118 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cristiangraz/kumi"
+	"github.com/cristiangraz/kumi/middleware"
+	"github.com/cristiangraz/kumi/router"
+)
+
+func TestRateLimit(t *testing.T) {
+	const burst = 3
+	k := kumi.New(router.NewHTTPRouter())
+	k.Use(middleware.RateLimit(1, burst))
+	k.Get("/", func(w http.ResponseWriter, r *http.Request) {})
+
+	request := func(addr string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("GET", "/", nil)
+		r.RemoteAddr = addr
+		w := httptest.NewRecorder()
+		k.ServeHTTP(w, r)
+		return w
+	}
+
+	for i := 0; i < burst; i++ {
+		if w := request("10.0.0.1:1234"); w.Code != http.StatusOK {
+			t.Fatalf("TestRateLimit (%d): Expected status 200, given %d", i, w.Code)
+		}
+	}
+
+	w := request("10.0.0.1:5678")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	} else if w.Header().Get("Retry-After") != "1" {
+		t.Fatalf("unexpected Retry-After: %s", w.Header().Get("Retry-After"))
+	}
+
+	// Other clients have their own bucket.
+	if w := request("10.0.0.2:1234"); w.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	}
+}
+
+func TestRateLimit_KeyFunc(t *testing.T) {
+	k := kumi.New(router.NewHTTPRouter())
+	k.Use(middleware.RateLimitWithOptions(&middleware.RateLimitOptions{
+		RPS:   1,
+		Burst: 1,
+		KeyFunc: func(r *http.Request) string {
+			return r.Header.Get("X-Forwarded-For")
+		},
+	}))
+	k.Get("/", func(w http.ResponseWriter, r *http.Request) {})
+
+	codes := make([]int, 0, 3)
+	for _, ip := range []string{"1.1.1.1", "2.2.2.2", "1.1.1.1"} {
+		r, _ := http.NewRequest("GET", "/", nil)
+		r.Header.Set("X-Forwarded-For", ip)
+		w := httptest.NewRecorder()
+		k.ServeHTTP(w, r)
+		codes = append(codes, w.Code)
+	}
+
+	if codes[0] != http.StatusOK || codes[1] != http.StatusOK || codes[2] != http.StatusTooManyRequests {
+		t.Fatalf("unexpected status codes: %v", codes)
+	}
+}
+
+// fakeRateLimitStore allows requests from keys in allow.
+type fakeRateLimitStore struct {
+	allow map[string]bool
+	err   error
+	keys  []string
+}
+
+func (s *fakeRateLimitStore) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	s.keys = append(s.keys, key)
+	return s.allow[key], 2500 * time.Millisecond, s.err
+}
+
+func TestRateLimit_Store(t *testing.T) {
+	tests := []struct {
+		addr       string
+		err        error
+		code       int
+		retryAfter string
+	}{
+		{addr: "10.0.0.1:1234", code: http.StatusOK},
+		{addr: "10.0.0.2:1234", code: http.StatusTooManyRequests, retryAfter: "3"},
+		{addr: "10.0.0.2:1234", err: errors.New("store unavailable"), code: http.StatusOK},
+	}
+
+	for i, tt := range tests {
+		store := &fakeRateLimitStore{allow: map[string]bool{"10.0.0.1": true}, err: tt.err}
+		k := kumi.New(router.NewHTTPRouter())
+		k.Use(middleware.RateLimitWithOptions(&middleware.RateLimitOptions{Store: store}))
+		k.Get("/", func(w http.ResponseWriter, r *http.Request) {})
+
+		r, _ := http.NewRequest("GET", "/", nil)
+		r.RemoteAddr = tt.addr
+		w := httptest.NewRecorder()
+		k.ServeHTTP(w, r)
+
+		if w.Code != tt.code {
+			t.Fatalf("TestRateLimit_Store (%d): Expected status %d, given %d", i, tt.code, w.Code)
+		} else if given := w.Header().Get("Retry-After"); given != tt.retryAfter {
+			t.Fatalf("TestRateLimit_Store (%d): Expected Retry-After %q, given %q", i, tt.retryAfter, given)
+		} else if expected := strings.Split(tt.addr, ":")[0]; len(store.keys) != 1 || store.keys[0] != expected {
+			t.Fatalf("TestRateLimit_Store (%d): Expected Allow to be called with %s, given %v", i, expected, store.keys)
+		}
+	}
+}
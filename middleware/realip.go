@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// RealIP returns middleware that sets r.RemoteAddr to the client's IP when
+// the request comes from a trusted proxy. X-Forwarded-For is read from
+// right to left, skipping trusted proxies, and the first untrusted IP is
+// used. If X-Forwarded-For is missing, X-Real-IP is used instead. Requests
+// connecting from any other IP are left untouched so clients cannot spoof
+// their IP. The rewritten RemoteAddr holds only the IP, without a port.
+func RealIP(trustedProxies []net.IPNet) func(http.Handler) http.Handler {
+	trusted := func(ip net.IP) bool {
+		for _, n := range trustedProxies {
+			if n.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if ip := net.ParseIP(remoteIP(r)); ip != nil && trusted(ip) {
+				if client := clientIP(r, trusted); client != nil {
+					r.RemoteAddr = client.String()
+				}
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// clientIP returns the right-most untrusted IP in X-Forwarded-For, or
+// X-Real-IP if X-Forwarded-For is not set. If every forwarded IP is
+// trusted the left-most is returned. nil is returned if the IP is invalid.
+func clientIP(r *http.Request, trusted func(net.IP) bool) net.IP {
+	var hops []string
+	for _, v := range r.Header["X-Forwarded-For"] {
+		hops = append(hops, strings.Split(v, ",")...)
+	}
+	if len(hops) == 0 {
+		return net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP")))
+	}
+
+	var ip net.IP
+	for i := len(hops) - 1; i >= 0; i-- {
+		if ip = net.ParseIP(strings.TrimSpace(hops[i])); ip == nil || !trusted(ip) {
+			return ip
+		}
+	}
+	return ip
+}
@@ -0,0 +1,83 @@
+package middleware_test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cristiangraz/kumi"
+	"github.com/cristiangraz/kumi/middleware"
+	"github.com/cristiangraz/kumi/router"
+)
+
+func TestRealIP(t *testing.T) {
+	_, lb, _ := net.ParseCIDR("10.0.0.0/8")
+	_, cdn, _ := net.ParseCIDR("2001:db8::/32")
+	trusted := []net.IPNet{*lb, *cdn}
+
+	tests := []struct {
+		remoteAddr string
+		xff        []string
+		xRealIP    string
+		want       string
+	}{
+		{ // spoofed XFF from an untrusted client
+			remoteAddr: "203.0.113.5:1234",
+			xff:        []string{"1.2.3.4"},
+			want:       "203.0.113.5:1234",
+		},
+		{ // legitimate proxy chain
+			remoteAddr: "10.0.0.1:1234",
+			xff:        []string{"198.51.100.7, 10.0.0.2"},
+			want:       "198.51.100.7",
+		},
+		{ // spoofed entry prepended by the client is skipped
+			remoteAddr: "10.0.0.1:1234",
+			xff:        []string{"1.2.3.4", "198.51.100.7, 2001:db8::1"},
+			want:       "198.51.100.7",
+		},
+		{ // all proxies trusted
+			remoteAddr: "10.0.0.1:1234",
+			xff:        []string{"10.0.0.3, 10.0.0.2"},
+			want:       "10.0.0.3",
+		},
+		{ // X-Real-IP from a trusted proxy
+			remoteAddr: "10.0.0.1:1234",
+			xRealIP:    "198.51.100.7",
+			want:       "198.51.100.7",
+		},
+		{ // invalid XFF entry
+			remoteAddr: "10.0.0.1:1234",
+			xff:        []string{"198.51.100.7, garbage"},
+			want:       "10.0.0.1:1234",
+		},
+		{ // no forwarding headers
+			remoteAddr: "10.0.0.1:1234",
+			want:       "10.0.0.1:1234",
+		},
+	}
+
+	for i, tt := range tests {
+		var given string
+		k := kumi.New(router.NewHTTPRouter())
+		k.Use(middleware.RealIP(trusted))
+		k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			given = r.RemoteAddr
+		})
+
+		r, _ := http.NewRequest("GET", "/", nil)
+		r.RemoteAddr = tt.remoteAddr
+		for _, v := range tt.xff {
+			r.Header.Add("X-Forwarded-For", v)
+		}
+		if tt.xRealIP != "" {
+			r.Header.Set("X-Real-IP", tt.xRealIP)
+		}
+		k.ServeHTTP(httptest.NewRecorder(), r)
+
+		if given != tt.want {
+			t.Errorf("(%d): expected RemoteAddr %q, given %q", i, tt.want, given)
+		}
+	}
+}
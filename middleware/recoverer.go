@@ -3,6 +3,9 @@ package middleware
 import (
 	"net/http"
 	"runtime/debug"
+
+	"github.com/cristiangraz/kumi"
+	"github.com/cristiangraz/kumi/api"
 )
 
 // Recoverer returns a recoverer function to recover from panics.
@@ -18,3 +21,40 @@ func Recoverer(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// Recover returns middleware that recovers from panics and passes the
+// recovered value to onPanic. If onPanic is nil, a 500 api.Failure is sent
+// unless the response has already been written to. http.ErrAbortHandler
+// is not recovered.
+func Recover(onPanic func(w http.ResponseWriter, r *http.Request, v interface{})) func(http.Handler) http.Handler {
+	if onPanic == nil {
+		onPanic = sendServerError
+	}
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				if v := recover(); v != nil {
+					if v == http.ErrAbortHandler {
+						panic(v)
+					}
+					onPanic(w, r, v)
+				}
+			}()
+
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// sendServerError sends a 500 api.Failure if nothing has been written.
+func sendServerError(w http.ResponseWriter, r *http.Request, v interface{}) {
+	if rw, ok := w.(kumi.ResponseWriter); ok && rw.HeaderWritten() {
+		return
+	}
+
+	api.Failure(http.StatusInternalServerError, api.Error{
+		Type:    "server_error",
+		Message: "An unexpected error occurred",
+	}).Send(w)
+}
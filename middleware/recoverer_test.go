@@ -0,0 +1,90 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cristiangraz/kumi"
+	"github.com/cristiangraz/kumi/middleware"
+	"github.com/cristiangraz/kumi/router"
+)
+
+func TestRecover(t *testing.T) {
+	k := kumi.New(router.NewHTTPRouter())
+	k.Use(middleware.Recover(nil))
+	k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	k.ServeHTTP(w, r)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	} else if w.Body.String() != `{"success":false,"status":500,"code":"internal_server_error","errors":[{"type":"server_error","message":"An unexpected error occurred"}]}`+"\n" {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+}
+
+func TestRecover_AlreadyWritten(t *testing.T) {
+	k := kumi.New(router.NewHTTPRouter())
+	k.Use(middleware.Recover(nil))
+	k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("partial"))
+		panic("boom")
+	})
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	k.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	} else if w.Body.String() != "partial" {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+}
+
+// Ensures no error is sent after an explicit 200 with an empty body.
+func TestRecover_HeaderWritten(t *testing.T) {
+	k := kumi.New(router.NewHTTPRouter())
+	k.Use(middleware.Recover(nil))
+	k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		panic("boom")
+	})
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	k.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	} else if w.Body.Len() != 0 {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+}
+
+func TestRecover_Callback(t *testing.T) {
+	var recovered interface{}
+	k := kumi.New(router.NewHTTPRouter())
+	k.Use(middleware.Recover(func(w http.ResponseWriter, r *http.Request, v interface{}) {
+		recovered = v
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	k.ServeHTTP(w, r)
+
+	if recovered != "boom" {
+		t.Fatalf("unexpected recovered value: %v", recovered)
+	} else if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	}
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/cristiangraz/kumi"
+)
+
+// RequestIDHeader is the header used to receive and send request IDs.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength is the longest incoming request ID that is accepted.
+const maxRequestIDLength = 128
+
+// RequestID returns middleware that propagates request IDs between
+// services. The ID from the X-Request-ID header is used if it is valid,
+// otherwise a new ID is generated. The ID is set on the request with
+// kumi.SetRequestID, so it is available from kumi.RequestID, and is sent
+// back in the X-Request-ID response header.
+func RequestID() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(RequestIDHeader)
+			if !validRequestID(id) {
+				id = kumi.NewRequestID()
+			}
+
+			w.Header().Set(RequestIDHeader, id)
+			next.ServeHTTP(w, kumi.SetRequestID(r, id))
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// validRequestID reports whether id is a reasonable length and only
+// contains characters found in common ID formats such as UUIDs and ULIDs.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		switch c := id[i]; {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
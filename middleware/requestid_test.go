@@ -0,0 +1,53 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/cristiangraz/kumi"
+	"github.com/cristiangraz/kumi/middleware"
+	"github.com/cristiangraz/kumi/router"
+)
+
+func TestRequestID(t *testing.T) {
+	generated := regexp.MustCompile(`^[0-9a-f]{32}$`)
+	tests := []struct {
+		header string
+		want   string // empty if the ID should be generated
+	}{
+		{header: "01ARZ3NDEKTSV4RRFFQ69G5FAV", want: "01ARZ3NDEKTSV4RRFFQ69G5FAV"},
+		{header: "f47ac10b-58cc-4372-a567-0e02b2c3d479", want: "f47ac10b-58cc-4372-a567-0e02b2c3d479"},
+		{header: ""},
+		{header: "bad id<script>"},
+		{header: strings.Repeat("a", 129)},
+	}
+
+	for i, tt := range tests {
+		var fromRequest, fromContext string
+		k := kumi.New(router.NewHTTPRouter())
+		k.Use(middleware.RequestID())
+		k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			fromRequest = kumi.RequestID(r)
+			fromContext = kumi.Context(r).RequestID()
+		})
+
+		r, _ := http.NewRequest("GET", "/", nil)
+		if tt.header != "" {
+			r.Header.Set("X-Request-ID", tt.header)
+		}
+		w := httptest.NewRecorder()
+		k.ServeHTTP(w, r)
+
+		given := w.Header().Get("X-Request-ID")
+		if tt.want != "" && given != tt.want {
+			t.Errorf("(%d): expected request ID %q, given %q", i, tt.want, given)
+		} else if tt.want == "" && !generated.MatchString(given) {
+			t.Errorf("(%d): expected a generated request ID, given %q", i, given)
+		} else if fromRequest != given || fromContext != given {
+			t.Errorf("(%d): expected handler to see %q, given %q and %q", i, given, fromRequest, fromContext)
+		}
+	}
+}
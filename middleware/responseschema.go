@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/cristiangraz/kumi"
+	"github.com/cristiangraz/kumi/api"
+	"github.com/cristiangraz/kumi/api/validator"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// responseSchemaRules maps response schema errors to api errors.
+var responseSchemaRules = validator.Rules{
+	"*": []validator.Mapping{
+		{Type: "required", ErrorType: "required", Message: "Required field missing"},
+		{Type: "additional_property_not_allowed", ErrorType: "unknown_parameter", Message: "Unknown field sent"},
+		{Type: "invalid_type", ErrorType: "invalid_type", Message: "Field has an invalid type"},
+		{Type: "enum", ErrorType: "invalid_value", Message: "Field has an invalid value"},
+		{Type: "*", ErrorType: "invalid_parameter", Message: "Field is invalid"},
+	},
+}
+
+// teeResponseWriter writes the response while keeping a copy of the body.
+type teeResponseWriter struct {
+	kumi.ResponseWriter
+	buf bytes.Buffer
+}
+
+// Write writes the response and copies it to the buffer.
+func (w *teeResponseWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.buf.Write(b[:n])
+	return n, err
+}
+
+// ResponseSchema returns middleware that validates response bodies against
+// the JSON schema for the matching route and passes any errors to
+// onViolation. schemasByRoute is keyed by route pattern (i.e. /users/:id),
+// or by request path when the middleware wraps the Engine rather than
+// being added with Use. Routes defined with GetWithSchema are validated
+// against their own schema when schemasByRoute has none for them, so
+// schemasByRoute may be nil. The response itself is never altered.
+// Because every matching response is copied and validated, this is
+// intended for development only.
+//
+// The schemas in schemasByRoute are compiled immediately and
+// ResponseSchema panics if any of them are invalid. Schemas registered
+// with GetWithSchema are compiled on first use.
+func ResponseSchema(schemasByRoute map[string]gojsonschema.JSONLoader, onViolation func(route string, errs []api.Error)) func(http.Handler) http.Handler {
+	schemas := make(map[string]*gojsonschema.Schema, len(schemasByRoute))
+	for route, loader := range schemasByRoute {
+		schemas[route] = compileResponseSchema(route, loader)
+	}
+	var routeSchemas sync.Map
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			route := kumi.RoutePattern(r)
+			if route == "" {
+				route = r.URL.Path
+			}
+
+			schema, ok := schemas[route]
+			if !ok {
+				if loader, found := kumi.RouteResponseSchema(r); found {
+					s, loaded := routeSchemas.Load(route)
+					if !loaded {
+						s, _ = routeSchemas.LoadOrStore(route, compileResponseSchema(route, loader))
+					}
+					schema, ok = s.(*gojsonschema.Schema), true
+				}
+			}
+
+			rw, isKumi := w.(kumi.ResponseWriter)
+			if !ok || !isKumi || r.Method == kumi.HEAD {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			tw := &teeResponseWriter{ResponseWriter: rw}
+			next.ServeHTTP(tw, r)
+			if tw.buf.Len() == 0 {
+				return
+			}
+
+			result, err := schema.Validate(gojsonschema.NewBytesLoader(tw.buf.Bytes()))
+			if err != nil {
+				onViolation(route, []api.Error{{Type: "invalid_json", Message: "Response is not valid JSON"}})
+			} else if !result.Valid() {
+				onViolation(route, validator.Swap(result.Errors(), responseSchemaRules))
+			}
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// compileResponseSchema compiles the schema for route or panics.
+func compileResponseSchema(route string, loader gojsonschema.JSONLoader) *gojsonschema.Schema {
+	schema, err := gojsonschema.NewSchema(loader)
+	if err != nil {
+		panic(fmt.Sprintf("invalid response schema for %s: %s", route, err))
+	}
+	return schema
+}
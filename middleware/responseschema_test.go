@@ -0,0 +1,125 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/cristiangraz/kumi"
+	"github.com/cristiangraz/kumi/api"
+	"github.com/cristiangraz/kumi/middleware"
+	"github.com/cristiangraz/kumi/router"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+func TestResponseSchema(t *testing.T) {
+	schemas := map[string]gojsonschema.JSONLoader{
+		"/users": gojsonschema.NewStringLoader(`{
+			"type": "object",
+			"properties": {
+				"id": {"type": "integer"},
+				"name": {"type": "string"}
+			},
+			"required": ["id", "name"],
+			"additionalProperties": false
+		}`),
+	}
+
+	tests := []struct {
+		body   string
+		route  string
+		errors []api.Error
+	}{
+		{body: `{"id": 1, "name": "Jon"}`},
+		{
+			body:  `{"id": "1"}`,
+			route: "/users",
+			errors: []api.Error{
+				{Field: "name", Type: "required", Message: "Required field missing"},
+				{Field: "id", Type: "invalid_type", Message: "Field has an invalid type"},
+			},
+		},
+		{
+			body:   `{"id": 1,`,
+			route:  "/users",
+			errors: []api.Error{{Type: "invalid_json", Message: "Response is not valid JSON"}},
+		},
+	}
+
+	for i, tt := range tests {
+		var route string
+		var errs []api.Error
+		k := kumi.New(router.NewHTTPRouter())
+		k.Use(middleware.ResponseSchema(schemas, func(r string, e []api.Error) {
+			route, errs = r, e
+		}))
+		k.Get("/users", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(tt.body))
+		})
+
+		r, _ := http.NewRequest("GET", "/users", nil)
+		w := httptest.NewRecorder()
+		k.ServeHTTP(w, r)
+
+		if w.Body.String() != tt.body {
+			t.Fatalf("TestResponseSchema (%d): Expected body to be unaltered, given %s", i, w.Body.String())
+		} else if route != tt.route {
+			t.Fatalf("TestResponseSchema (%d): Expected route %q, given %q", i, tt.route, route)
+		} else if !reflect.DeepEqual(errs, tt.errors) {
+			t.Fatalf("TestResponseSchema (%d): Expected %#v, given %#v", i, tt.errors, errs)
+		}
+	}
+}
+
+func TestResponseSchema_RoutePattern(t *testing.T) {
+	user := `{
+		"type": "object",
+		"properties": {"id": {"type": "integer"}},
+		"required": ["id"]
+	}`
+
+	tests := []struct {
+		schemas  map[string]gojsonschema.JSONLoader
+		register func(k *kumi.Engine, h http.HandlerFunc)
+	}{
+		{
+			schemas: map[string]gojsonschema.JSONLoader{"/users/:id": gojsonschema.NewStringLoader(user)},
+			register: func(k *kumi.Engine, h http.HandlerFunc) {
+				k.Get("/users/:id", h)
+			},
+		},
+		{
+			register: func(k *kumi.Engine, h http.HandlerFunc) {
+				k.GetWithSchema("/users/:id", gojsonschema.NewStringLoader(user), h)
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		var route string
+		var errs []api.Error
+		k := kumi.New(router.NewHTTPRouter())
+		k.Use(middleware.ResponseSchema(tt.schemas, func(r string, e []api.Error) {
+			route, errs = r, e
+		}))
+		tt.register(k, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"name": "Jon"}`))
+		})
+
+		for j := 0; j < 2; j++ {
+			route, errs = "", nil
+			r, _ := http.NewRequest("GET", "/users/1", nil)
+			k.ServeHTTP(httptest.NewRecorder(), r)
+
+			expected := []api.Error{{Field: "id", Type: "required", Message: "Required field missing"}}
+			if route != "/users/:id" {
+				t.Fatalf("TestResponseSchema_RoutePattern (%d): Expected route %q, given %q", i, "/users/:id", route)
+			} else if !reflect.DeepEqual(errs, expected) {
+				t.Fatalf("TestResponseSchema_RoutePattern (%d): Expected %#v, given %#v", i, expected, errs)
+			}
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/cristiangraz/kumi"
+)
+
+// ServerTiming returns middleware that sets the Server-Timing response
+// header from the spans recorded with kumi.Context(r).StartSpan. The
+// header is set when the response header is written, so only spans that
+// have finished by then are included.
+func ServerTiming() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			sw := newResponseWriter(w)
+			sw.header = func(s int) int {
+				kumi.SetServerTiming(w, r)
+				return s
+			}
+			next.ServeHTTP(&sw, r)
+			if !sw.wroteHeader {
+				kumi.SetServerTiming(w, r)
+			}
+		}
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,49 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/cristiangraz/kumi"
+	"github.com/cristiangraz/kumi/middleware"
+	"github.com/cristiangraz/kumi/router"
+)
+
+func TestServerTiming(t *testing.T) {
+	k := kumi.New(router.NewHTTPRouter())
+	k.Use(middleware.ServerTiming())
+	k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		rc := kumi.Context(r)
+		end := rc.StartSpan("db")
+		time.Sleep(2 * time.Millisecond)
+		end()
+		rc.StartSpan("render")()
+
+		w.Write([]byte("hello"))
+		rc.StartSpan("late")() // After the header is written.
+	})
+	k.Get("/empty", func(w http.ResponseWriter, r *http.Request) {
+		kumi.Context(r).StartSpan("db")()
+	})
+
+	tests := []struct {
+		path string
+		want *regexp.Regexp
+	}{
+		{path: "/", want: regexp.MustCompile(`^db;dur=[0-9.]+, render;dur=[0-9.]+$`)},
+		{path: "/empty", want: regexp.MustCompile(`^db;dur=[0-9.]+$`)},
+	}
+
+	for i, tt := range tests {
+		r, _ := http.NewRequest("GET", tt.path, nil)
+		w := httptest.NewRecorder()
+		k.ServeHTTP(w, r)
+
+		if h := w.Header().Get("Server-Timing"); !tt.want.MatchString(h) {
+			t.Fatalf("TestServerTiming (%d): Unexpected Server-Timing header: %s", i, h)
+		}
+	}
+}
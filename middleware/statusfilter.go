@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/cristiangraz/kumi"
+)
+
+// statusFilterWriter holds the status code until the first non-empty
+// write, or until the handler returns, so it can be replaced.
+type statusFilterWriter struct {
+	responseWriter
+	r         *http.Request
+	fn        func(r *http.Request, status int, empty bool) int
+	committed bool
+}
+
+var _ kumi.ResponseWriter = &statusFilterWriter{}
+
+// WriteHeader records the status code without writing it.
+func (w *statusFilterWriter) WriteHeader(s int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.status = s
+}
+
+// Write commits the status code and writes the response.
+func (w *statusFilterWriter) Write(b []byte) (int, error) {
+	if len(b) == 0 {
+		return 0, nil
+	}
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.committed {
+		w.commit(false)
+	}
+	return w.responseWriter.Write(b)
+}
+
+// commit passes the status code through the filter and writes it.
+func (w *statusFilterWriter) commit(empty bool) {
+	w.committed = true
+	w.status = w.fn(w.r, w.status, empty)
+	w.ResponseWriter.WriteHeader(w.status)
+}
+
+// StatusFilter returns middleware that lets fn inspect and replace the
+// status code before it is written. fn runs once, either on the first
+// non-empty write or, with empty set to true, after the handler returns
+// without writing a body. fn only decides the status code; it does not
+// receive the ResponseWriter, so headers must be set by the handler.
+//
+// Middleware that buffers the response, such as Compressor and Minify,
+// only sees the filtered status if it is added before StatusFilter.
+func StatusFilter(fn func(r *http.Request, status int, empty bool) int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sw := &statusFilterWriter{responseWriter: newResponseWriter(w), r: r, fn: fn}
+			next.ServeHTTP(sw, r)
+			if !sw.committed {
+				sw.commit(true)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cristiangraz/kumi"
+	"github.com/cristiangraz/kumi/middleware"
+	"github.com/cristiangraz/kumi/router"
+)
+
+func TestStatusFilter(t *testing.T) {
+	noContent := func(r *http.Request, status int, empty bool) int {
+		if status == http.StatusOK && empty {
+			return http.StatusNoContent
+		}
+		return status
+	}
+
+	tests := []struct {
+		body       string
+		statusCode int
+	}{
+		{statusCode: http.StatusNoContent},
+		{body: "hello", statusCode: http.StatusOK},
+	}
+
+	for i, tt := range tests {
+		k := kumi.New(router.NewHTTPRouter())
+		k.Use(middleware.StatusFilter(noContent))
+		k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(tt.body))
+		})
+
+		r, _ := http.NewRequest("GET", "/", nil)
+		w := httptest.NewRecorder()
+		k.ServeHTTP(w, r)
+
+		if w.Code != tt.statusCode {
+			t.Fatalf("TestStatusFilter (%d): Expected status %d, given %d", i, tt.statusCode, w.Code)
+		} else if w.Body.String() != tt.body {
+			t.Fatalf("TestStatusFilter (%d): Expected body %q, given %q", i, tt.body, w.Body.String())
+		}
+	}
+}
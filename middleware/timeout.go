@@ -1,27 +1,217 @@
 package middleware
 
 import (
+	"bufio"
+	"context"
+	"errors"
+	"log"
+	"net"
 	"net/http"
+	"runtime/debug"
+	"sync"
 	"time"
 
-	"context"
+	"github.com/cristiangraz/kumi"
+	"github.com/cristiangraz/kumi/api"
 )
 
-// Timeout cancels context.Context after a given duration.
+// timeoutWriter guards the response so the handler goroutine and the
+// timeout cannot both write to it. Headers set by the handler are held
+// until the status code is written.
+type timeoutWriter struct {
+	ctx         context.Context
+	w           http.ResponseWriter
+	h           http.Header
+	mu          sync.Mutex
+	timedOut    bool
+	wroteHeader bool
+	status      int
+	n           int
+}
+
+var _ kumi.ResponseWriter = &timeoutWriter{}
+
+// Header returns the handler's header map.
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.h
+}
+
+// WriteHeader copies the handler's headers and writes the status code
+// unless the request has already timed out.
+func (tw *timeoutWriter) WriteHeader(s int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	tw.writeHeader(s)
+}
+
+func (tw *timeoutWriter) writeHeader(s int) {
+	if tw.expired() || tw.wroteHeader {
+		return
+	}
+	tw.wroteHeader = true
+	tw.status = s
+	copyHeader(tw.w.Header(), tw.h)
+	tw.w.WriteHeader(s)
+}
+
+// Write writes the response or returns http.ErrHandlerTimeout if the
+// request has timed out.
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.expired() {
+		return 0, http.ErrHandlerTimeout
+	}
+	tw.writeHeader(http.StatusOK)
+	n, err := tw.w.Write(b)
+	tw.n += n
+	return n, err
+}
+
+// Status returns the status code for the response.
+func (tw *timeoutWriter) Status() int {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	return tw.status
+}
+
+// Written returns the number of bytes written.
+func (tw *timeoutWriter) Written() int {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	return tw.n
+}
+
+// HeaderWritten returns true once the status code has been written.
+func (tw *timeoutWriter) HeaderWritten() bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	return tw.wroteHeader
+}
+
+// Flush writes the status code and flushes the response unless the
+// request has timed out. Once flushed, the timeout response can no longer
+// be sent.
+func (tw *timeoutWriter) Flush() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	f, ok := tw.w.(http.Flusher)
+	if tw.expired() || !ok {
+		return
+	}
+	tw.writeHeader(http.StatusOK)
+	f.Flush()
+}
+
+// Hijack implements the http.Hijacker interface. Once hijacked, the
+// timeout no longer writes a response; the handler owns the connection.
+func (tw *timeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.expired() {
+		return nil, nil, http.ErrHandlerTimeout
+	}
+	h, ok := tw.w.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("the response writer doesn't support the http.Hijacker interface")
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil {
+		tw.wroteHeader = true
+	}
+	return conn, rw, err
+}
+
+// Push implements the http.Pusher interface. http.ErrNotSupported is
+// returned if the underlying writer does not support server push.
+func (tw *timeoutWriter) Push(target string, opts *http.PushOptions) error {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.expired() {
+		return http.ErrHandlerTimeout
+	}
+	if p, ok := tw.w.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+// expired returns true once the deadline has passed, even if Timeout
+// has not yet sent its response, so a handler woken by the deadline
+// cannot race the timeout response.
+func (tw *timeoutWriter) expired() bool {
+	return tw.timedOut || tw.ctx.Err() == context.DeadlineExceeded
+}
+
+func copyHeader(dst, src http.Header) {
+	for k, v := range src {
+		dst[k] = v
+	}
+}
+
+// Timeout runs the handler with a context that is cancelled after a given
+// duration. If the handler has not written a response when the duration
+// elapses, a 503 Service Unavailable api.Failure is sent and any later
+// writes by the handler return http.ErrHandlerTimeout. If the request is
+// cancelled first, i.e. the client went away, nothing is sent. The
+// handler keeps running after the timeout, so kumi's RequestContext is
+// retained until it returns; a panic at that point can no longer reach
+// the caller and is logged instead.
 func Timeout(timeout time.Duration) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
 			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
 
-			defer func() {
-				cancel()
-				if ctx.Err() == context.DeadlineExceeded {
-					w.WriteHeader(http.StatusGatewayTimeout)
-				}
+			tw := &timeoutWriter{ctx: ctx, w: w, h: make(http.Header), status: http.StatusOK}
+			done, returned := make(chan struct{}), make(chan struct{})
+			defer close(returned)
+			panicch := make(chan interface{})
+			release := kumi.RetainContext(r)
+			go func() {
+				defer release()
+				defer func() {
+					p := recover()
+					if p == nil {
+						return
+					}
+					select {
+					case panicch <- p:
+					case <-returned:
+						if p != http.ErrAbortHandler {
+							log.Printf("timeout: panic serving %s after the timeout: %v\n%s", r.URL.Path, p, debug.Stack())
+						}
+					}
+				}()
+				next.ServeHTTP(tw, r.WithContext(ctx))
+				close(done)
 			}()
 
-			r = r.WithContext(ctx)
-			next.ServeHTTP(w, r)
+			var finished bool
+			select {
+			case p := <-panicch:
+				panic(p)
+			case <-done:
+				finished = true
+			case <-ctx.Done():
+			}
+
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			if finished && ctx.Err() != context.DeadlineExceeded {
+				if !tw.wroteHeader {
+					copyHeader(w.Header(), tw.h)
+				}
+				return
+			}
+
+			tw.timedOut = true
+			if !tw.wroteHeader && ctx.Err() == context.DeadlineExceeded {
+				api.Failure(http.StatusServiceUnavailable, api.Error{
+					Type:    "timeout",
+					Message: "The request timed out",
+				}).Send(w)
+			}
 		}
 		return http.HandlerFunc(fn)
 	}
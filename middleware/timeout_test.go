@@ -0,0 +1,189 @@
+package middleware_test
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cristiangraz/kumi"
+	"github.com/cristiangraz/kumi/middleware"
+	"github.com/cristiangraz/kumi/router"
+)
+
+func TestTimeout(t *testing.T) {
+	k := kumi.New(router.NewHTTPRouter())
+	k.Use(middleware.Timeout(time.Second))
+	k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Handler", "true")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("hello world"))
+	})
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	k.ServeHTTP(w, r)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	} else if w.Header().Get("X-Handler") != "true" {
+		t.Fatal("expected X-Handler header")
+	} else if w.Body.String() != "hello world" {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+}
+
+func TestTimeout_Exceeded(t *testing.T) {
+	errch := make(chan error, 1)
+	k := kumi.New(router.NewHTTPRouter())
+	k.Use(middleware.Timeout(10 * time.Millisecond))
+	k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		w.Header().Set("X-Handler", "true")
+		_, err := w.Write([]byte("too late"))
+		errch <- err
+	})
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	k.ServeHTTP(w, r)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	} else if w.Header().Get("X-Handler") != "" {
+		t.Fatal("unexpected X-Handler header")
+	} else if w.Body.String() != `{"success":false,"status":503,"code":"service_unavailable","errors":[{"type":"timeout","message":"The request timed out"}]}`+"\n" {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+
+	if err := <-errch; err != http.ErrHandlerTimeout {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensures no timeout response is sent when the request is cancelled.
+func TestTimeout_Cancelled(t *testing.T) {
+	k := kumi.New(router.NewHTTPRouter())
+	k.Use(middleware.Timeout(time.Second))
+	k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	k.ServeHTTP(w, r.WithContext(ctx))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	} else if w.Body.Len() != 0 {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+}
+
+// logWriter sends each log line on a channel.
+type logWriter chan string
+
+func (w logWriter) Write(b []byte) (int, error) {
+	w <- string(b)
+	return len(b), nil
+}
+
+// Ensures a panic after the timeout response has been sent is logged.
+func TestTimeout_LatePanic(t *testing.T) {
+	logs := make(logWriter, 1)
+	log.SetOutput(logs)
+	defer log.SetOutput(os.Stderr)
+
+	k := kumi.New(router.NewHTTPRouter())
+	k.Use(middleware.Timeout(10 * time.Millisecond))
+	k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		time.Sleep(10 * time.Millisecond)
+		panic("boom")
+	})
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	k.ServeHTTP(w, r)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	}
+
+	select {
+	case line := <-logs:
+		if !strings.Contains(line, "boom") {
+			t.Fatalf("unexpected log: %s", line)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the panic to be logged")
+	}
+}
+
+// Ensures the RequestContext is not reused while a timed out handler is
+// still running. Run with -race.
+func TestTimeout_RetainsContext(t *testing.T) {
+	done := make(chan string, 1)
+	k := kumi.New(router.NewHTTPRouter())
+	k.Use(middleware.Timeout(10 * time.Millisecond))
+	k.Get("/users/:id", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/users/1" {
+			<-r.Context().Done()
+			time.Sleep(20 * time.Millisecond)
+		}
+		id := kumi.Context(r).Params().Get("id")
+		kumi.Context(r).Query().Get("q")
+		kumi.Context(r).StartSpan("db")()
+		if r.URL.Path == "/users/1" {
+			done <- id
+		}
+	})
+
+	r, _ := http.NewRequest("GET", "/users/1", nil)
+	w := httptest.NewRecorder()
+	k.ServeHTTP(w, r)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	}
+
+	// Serve more requests while the first handler is still running so
+	// its context would be reused if it had been returned to the pool.
+	for i := 0; i < 50; i++ {
+		r, _ := http.NewRequest("GET", "/users/2", nil)
+		k.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	if id := <-done; id != "1" {
+		t.Fatalf("unexpected id param: %q", id)
+	}
+}
+
+func TestTimeout_Flush(t *testing.T) {
+	k := kumi.New(router.NewHTTPRouter())
+	k.Use(middleware.Timeout(time.Second))
+	k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("chunk"))
+		w.(http.Flusher).Flush()
+		if err := w.(http.Pusher).Push("/app.js", nil); err != http.ErrNotSupported {
+			t.Errorf("unexpected push error: %v", err)
+		}
+		if _, _, err := w.(http.Hijacker).Hijack(); err == nil {
+			t.Error("expected hijack error")
+		}
+	})
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	k.ServeHTTP(w, r)
+
+	if !w.Flushed {
+		t.Fatal("expected response to be flushed")
+	} else if w.Body.String() != "chunk" {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/cristiangraz/kumi"
+)
+
+// RedirectTrailingSlash redirects GET and HEAD requests with a trailing
+// slash to the same path without it using the given status code
+// (http.StatusMovedPermanently or http.StatusPermanentRedirect). The root
+// path and all other methods are untouched. Because middleware added with
+// Use only runs on matched routes, wrap the Engine with this middleware.
+func RedirectTrailingSlash(code int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != kumi.GET && r.Method != kumi.HEAD {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			p := r.URL.EscapedPath()
+			if len(p) <= 1 || !strings.HasSuffix(p, "/") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Never redirect to a protocol-relative URL (i.e. //example.com).
+			target := strings.TrimRight(p, "/")
+			if target == "" || strings.HasPrefix(target, "//") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+			w.Header().Set("Location", target)
+			w.WriteHeader(code)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,45 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cristiangraz/kumi/middleware"
+)
+
+func TestRedirectTrailingSlash(t *testing.T) {
+	tests := []struct {
+		method     string
+		url        string
+		code       int
+		statusCode int
+		location   string
+	}{
+		{method: "GET", url: "/users/", code: http.StatusMovedPermanently, statusCode: http.StatusMovedPermanently, location: "/users"},
+		{method: "HEAD", url: "/users/?limit=10", code: http.StatusPermanentRedirect, statusCode: http.StatusPermanentRedirect, location: "/users?limit=10"},
+		{method: "GET", url: "/", code: http.StatusMovedPermanently, statusCode: http.StatusOK},
+		{method: "GET", url: "/users", code: http.StatusMovedPermanently, statusCode: http.StatusOK},
+		{method: "POST", url: "/users/", code: http.StatusMovedPermanently, statusCode: http.StatusOK},
+		{method: "GET", url: "//example.com/", code: http.StatusMovedPermanently, statusCode: http.StatusOK},
+	}
+
+	for i, tt := range tests {
+		var ran bool
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ran = true
+		})
+
+		r, _ := http.NewRequest(tt.method, tt.url, nil)
+		w := httptest.NewRecorder()
+		middleware.RedirectTrailingSlash(tt.code)(h).ServeHTTP(w, r)
+
+		if w.Code != tt.statusCode {
+			t.Fatalf("TestRedirectTrailingSlash (%d): Expected status %d, given %d", i, tt.statusCode, w.Code)
+		} else if location := w.Header().Get("Location"); location != tt.location {
+			t.Fatalf("TestRedirectTrailingSlash (%d): Expected location %q, given %q", i, tt.location, location)
+		} else if ran != (tt.location == "") {
+			t.Fatalf("TestRedirectTrailingSlash (%d): Expected handler ran to be %t", i, tt.location == "")
+		}
+	}
+}
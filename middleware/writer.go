@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/cristiangraz/kumi"
+)
+
+// responseWriter is the kumi.ResponseWriter embedded by the middleware
+// that wraps the response. It records the status code and the number of
+// bytes written. Wrappers that hold the status code or body back override
+// WriteHeader and Write and keep the fields up to date themselves.
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	n           int
+
+	// header, if set, is called before the status code is written and
+	// returns the status code to write, so a wrapper can set headers or
+	// replace the status code.
+	header func(status int) int
+}
+
+var _ kumi.ResponseWriter = &responseWriter{}
+
+// newResponseWriter returns a responseWriter wrapping w.
+func newResponseWriter(w http.ResponseWriter) responseWriter {
+	return responseWriter{ResponseWriter: w, status: http.StatusOK}
+}
+
+// WriteHeader writes the status code once.
+func (w *responseWriter) WriteHeader(s int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if w.header != nil {
+		s = w.header(s)
+	}
+	w.status = s
+	w.ResponseWriter.WriteHeader(s)
+}
+
+// Write writes the response.
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.n += n
+	return n, err
+}
+
+// Status returns the status code for the response.
+func (w *responseWriter) Status() int {
+	return w.status
+}
+
+// Written returns the number of bytes written.
+func (w *responseWriter) Written() int {
+	return w.n
+}
+
+// HeaderWritten returns true once the status code has been written.
+func (w *responseWriter) HeaderWritten() bool {
+	return w.wroteHeader
+}
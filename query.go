@@ -1,11 +1,17 @@
 package kumi
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
 	"regexp"
 	"sort"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cristiangraz/kumi/api"
 )
 
 // Query provides useful methods to operate on the request's query string values.
@@ -15,6 +21,9 @@ type Query struct {
 
 var csvIDs = regexp.MustCompile(`^[0-9]+(?:,[0-9]+)*$`)
 
+// ErrMissingQueryParam is returned when a query string value is absent or empty.
+var ErrMissingQueryParam = errors.New("query: parameter missing")
+
 // NewQuery creates a new Query from a http.Request.
 func NewQuery(r *http.Request) *Query {
 	return &Query{request: r}
@@ -44,6 +53,75 @@ func (q Query) GetInt(name string) (int, error) {
 	return strconv.Atoi(q.Get(name))
 }
 
+// GetFloat attempts to convert a query string value to a float64.
+func (q Query) GetFloat(name string) (float64, error) {
+	return strconv.ParseFloat(q.Get(name), 64)
+}
+
+// GetFloatDefault attempts to convert a query string value to a float64.
+// If the value does not exist or cannot be converted, defaultValue is
+// returned instead.
+func (q Query) GetFloatDefault(name string, defaultValue float64) float64 {
+	if f, err := q.GetFloat(name); err == nil {
+		return f
+	}
+	return defaultValue
+}
+
+// GetInts converts a comma-separated query string value to a slice of
+// integers (i.e. ?ids=1,2,3). An error is returned if the value is empty
+// or is not a list of integers.
+func (q Query) GetInts(name string) ([]int, error) {
+	v := q.Get(name)
+	if !csvIDs.MatchString(v) {
+		return nil, fmt.Errorf("query: %s is not a comma-separated list of integers: %q", name, v)
+	}
+
+	parts := strings.Split(v, ",")
+	ints := make([]int, len(parts))
+	for i := range parts {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil {
+			return nil, err
+		}
+		ints[i] = n
+	}
+	return ints, nil
+}
+
+// GetStrings splits a comma-separated query string value into a slice
+// of strings. Each value is trimmed and empty values are removed.
+func (q Query) GetStrings(name string) []string {
+	var values []string
+	for _, v := range strings.Split(q.Get(name), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// GetTime parses a query string value as a time using layout.
+// ErrMissingQueryParam is returned if the value does not exist or is empty,
+// otherwise any error from time.Parse is returned.
+func (q Query) GetTime(name string, layout string) (time.Time, error) {
+	v := q.Get(name)
+	if v == "" {
+		return time.Time{}, ErrMissingQueryParam
+	}
+	return time.Parse(layout, v)
+}
+
+// GetTimeDefault parses a query string value as a time using layout.
+// If the value does not exist or cannot be parsed, defaultValue is
+// returned instead.
+func (q Query) GetTimeDefault(name string, layout string, defaultValue time.Time) time.Time {
+	if t, err := q.GetTime(name, layout); err == nil {
+		return t
+	}
+	return defaultValue
+}
+
 // GetBool returns the boolean value represented by the string.
 // It accepts 1, t, T, TRUE, true, True, 0, f, F, FALSE, false, False.
 // Any other value returns an error.
@@ -51,6 +129,46 @@ func (q Query) GetBool(name string) (bool, error) {
 	return strconv.ParseBool(q.Get(name))
 }
 
+// Require returns the names of any query string values that do not
+// exist or are empty.
+func (q Query) Require(names ...string) []string {
+	var missing []string
+	for _, name := range names {
+		if q.Get(name) == "" {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// Only returns a 400 api.Failure with an unknown_parameter error for each
+// query string key not in allowed, or nil if every key is allowed.
+func (q Query) Only(allowed ...string) api.Sender {
+	var unknown []string
+	for k := range q.All() {
+		var ok bool
+		for _, name := range allowed {
+			if k == name {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			unknown = append(unknown, k)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	sort.Strings(unknown)
+	errs := make([]api.Error, len(unknown))
+	for i, k := range unknown {
+		errs[i] = api.Error{Field: k, Type: "unknown_parameter", Message: "Unknown parameter sent"}
+	}
+	return api.Failure(http.StatusBadRequest, errs...)
+}
+
 // Sort returns the query string sorted with empty values removed.
 func (q *Query) Sort() url.Values {
 	var keys []string
@@ -2,9 +2,11 @@ package kumi_test
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/cristiangraz/kumi"
 )
@@ -43,3 +45,141 @@ func TestQuery(t *testing.T) {
 		t.Fatalf("unexpected value for sort: %v", q.Sort())
 	}
 }
+
+func TestQuery_GetFloat(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/?lat=37.7749&lng=-122.4194&empty=&bad=12.3.4", nil)
+	q := kumi.NewQuery(r)
+
+	if lat, err := q.GetFloat("lat"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if lat != 37.7749 {
+		t.Fatalf("unexpected lat: %f", lat)
+	} else if lng, err := q.GetFloat("lng"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if lng != -122.4194 {
+		t.Fatalf("unexpected lng: %f", lng)
+	} else if _, err := q.GetFloat("empty"); err == nil {
+		t.Fatal("expected error for empty value, none given")
+	} else if _, err := q.GetFloat("bad"); err == nil {
+		t.Fatal("expected error for malformed value, none given")
+	} else if _, err := q.GetFloat("missing"); err == nil {
+		t.Fatal("expected error for missing value, none given")
+	}
+
+	if f := q.GetFloatDefault("lat", 1.5); f != 37.7749 {
+		t.Fatalf("unexpected value: %f", f)
+	} else if f := q.GetFloatDefault("empty", 1.5); f != 1.5 {
+		t.Fatalf("unexpected value: %f", f)
+	} else if f := q.GetFloatDefault("bad", 1.5); f != 1.5 {
+		t.Fatalf("unexpected value: %f", f)
+	}
+}
+
+func TestQuery_GetInts(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/?one=5&ids=1,2,3&empty=&alpha=1,b,3&trailing=1,2,&spaces=1,+2", nil)
+	q := kumi.NewQuery(r)
+
+	tests := []struct {
+		name     string
+		expected []int
+		err      bool
+	}{
+		{name: "one", expected: []int{5}},
+		{name: "ids", expected: []int{1, 2, 3}},
+		{name: "empty", err: true},
+		{name: "missing", err: true},
+		{name: "alpha", err: true},
+		{name: "trailing", err: true},
+		{name: "spaces", err: true},
+	}
+
+	for i, tt := range tests {
+		given, err := q.GetInts(tt.name)
+		if tt.err && err == nil {
+			t.Errorf("TestQuery_GetInts (%d): Expected error, none given", i)
+		} else if !tt.err && err != nil {
+			t.Errorf("TestQuery_GetInts (%d): Unexpected error: %v", i, err)
+		} else if !reflect.DeepEqual(given, tt.expected) {
+			t.Errorf("TestQuery_GetInts (%d): Expected %v, given %v", i, tt.expected, given)
+		}
+	}
+}
+
+func TestQuery_GetStrings(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/?one=a&tags=a,b,c&sparse=a,,+b+,&empty=", nil)
+	q := kumi.NewQuery(r)
+
+	if given := q.GetStrings("one"); !reflect.DeepEqual(given, []string{"a"}) {
+		t.Fatalf("unexpected value: %v", given)
+	} else if given := q.GetStrings("tags"); !reflect.DeepEqual(given, []string{"a", "b", "c"}) {
+		t.Fatalf("unexpected value: %v", given)
+	} else if given := q.GetStrings("sparse"); !reflect.DeepEqual(given, []string{"a", "b"}) {
+		t.Fatalf("unexpected value: %v", given)
+	} else if given := q.GetStrings("empty"); given != nil {
+		t.Fatalf("unexpected value: %v", given)
+	}
+}
+
+func TestQuery_GetTime(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/?since=2017-01-02T15:04:05Z&day=2017-01-02&empty=&bad=yesterday", nil)
+	q := kumi.NewQuery(r)
+
+	if since, err := q.GetTime("since", time.RFC3339); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if !since.Equal(time.Date(2017, 1, 2, 15, 4, 5, 0, time.UTC)) {
+		t.Fatalf("unexpected time: %v", since)
+	} else if day, err := q.GetTime("day", "2006-01-02"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if !day.Equal(time.Date(2017, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected time: %v", day)
+	} else if _, err := q.GetTime("empty", time.RFC3339); err != kumi.ErrMissingQueryParam {
+		t.Fatalf("unexpected error: %v", err)
+	} else if _, err := q.GetTime("missing", time.RFC3339); err != kumi.ErrMissingQueryParam {
+		t.Fatalf("unexpected error: %v", err)
+	} else if _, err := q.GetTime("bad", time.RFC3339); err == nil || err == kumi.ErrMissingQueryParam {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	def := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	if given := q.GetTimeDefault("day", "2006-01-02", def); given.Equal(def) {
+		t.Fatalf("unexpected time: %v", given)
+	} else if given := q.GetTimeDefault("bad", time.RFC3339, def); !given.Equal(def) {
+		t.Fatalf("unexpected time: %v", given)
+	} else if given := q.GetTimeDefault("missing", time.RFC3339, def); !given.Equal(def) {
+		t.Fatalf("unexpected time: %v", given)
+	}
+}
+
+func TestQuery_Require(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/?page=2&limit=20&empty=", nil)
+	q := kumi.NewQuery(r)
+
+	if missing := q.Require("page", "limit"); len(missing) != 0 {
+		t.Fatalf("unexpected missing params: %v", missing)
+	} else if missing := q.Require("page", "empty", "order"); !reflect.DeepEqual(missing, []string{"empty", "order"}) {
+		t.Fatalf("unexpected missing params: %v", missing)
+	} else if missing := q.Require("foo", "bar"); !reflect.DeepEqual(missing, []string{"foo", "bar"}) {
+		t.Fatalf("unexpected missing params: %v", missing)
+	}
+}
+
+func TestQuery_Only(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/?limit=10&offset=5", nil)
+	if s := kumi.NewQuery(r).Only("limit", "offset", "order"); s != nil {
+		t.Fatalf("unexpected sender: %#v", s)
+	}
+
+	r, _ = http.NewRequest("GET", "/?limt=10&offset=5&x=1", nil)
+	s := kumi.NewQuery(r).Only("limit", "offset")
+	if s == nil {
+		t.Fatal("expected sender")
+	}
+
+	w := httptest.NewRecorder()
+	s.Send(w)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	} else if w.Body.String() != `{"success":false,"status":400,"code":"bad_request","errors":[{"field":"limt","type":"unknown_parameter","message":"Unknown parameter sent"},{"field":"x","type":"unknown_parameter","message":"Unknown parameter sent"}]}`+"\n" {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+}
@@ -0,0 +1,34 @@
+//go:build go1.22
+
+// Package quic serves kumi over HTTP/3.
+package quic
+
+import (
+	"crypto/tls"
+	"net/http"
+
+	"github.com/cristiangraz/kumi"
+	"github.com/quic-go/quic-go/http3"
+)
+
+// NewServer returns a Server that serves HTTP/3 over QUIC on the UDP
+// address addr. Add it to ServeConfig.Servers alongside a TCP server so
+// clients that discover HTTP/3 through an Alt-Svc header can upgrade.
+// Graceful shutdown stops accepting new connections and waits for
+// in-flight requests to finish before closing the QUIC connections.
+func NewServer(addr string, tlsConfig *tls.Config) kumi.Server {
+	srv := &http.Server{Addr: addr, TLSConfig: tlsConfig}
+	return kumi.Server{
+		Server: srv,
+		Transport: &http3.Server{
+			Addr:      addr,
+			TLSConfig: http3.ConfigureTLSConfig(tlsConfig),
+
+			// The handler is read when a request is served so that
+			// Engine.Serve can set it on the http.Server.
+			Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				srv.Handler.ServeHTTP(w, r)
+			}),
+		},
+	}
+}
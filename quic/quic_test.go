@@ -0,0 +1,78 @@
+//go:build go1.22
+
+package quic_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cristiangraz/kumi"
+	"github.com/cristiangraz/kumi/quic"
+	"github.com/cristiangraz/kumi/router"
+	"github.com/quic-go/quic-go/http3"
+)
+
+func TestNewServer(t *testing.T) {
+	// Borrow httptest's self-signed certificate and a matching client config.
+	ts := httptest.NewTLSServer(nil)
+	serverTLS := ts.TLS
+	clientTLS := ts.Client().Transport.(*http.Transport).TLSClientConfig
+	ts.Close()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := conn.LocalAddr().String()
+	conn.Close()
+
+	k := kumi.New(router.NewHTTPRouter())
+	k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errch := make(chan error, 1)
+	go func() {
+		errch <- k.Serve(&kumi.ServeConfig{
+			Context:          ctx,
+			InterruptTimeout: time.Second,
+			ContextTimeout:   time.Second,
+			Servers:          []kumi.Server{quic.NewServer(addr, serverTLS)},
+		})
+	}()
+
+	tr := &http3.Transport{TLSClientConfig: clientTLS}
+	defer tr.Close()
+	client := &http.Client{Transport: tr, Timeout: time.Second}
+
+	var body []byte
+	for i := 0; i < 20; i++ {
+		resp, err := client.Get("https://" + addr + "/")
+		if err != nil {
+			time.Sleep(50 * time.Millisecond)
+			continue
+		}
+		body, _ = io.ReadAll(resp.Body)
+		resp.Body.Close()
+		break
+	}
+	if string(body) != "hello" {
+		t.Fatalf("unexpected body: %q", body)
+	}
+
+	cancel()
+	select {
+	case err := <-errch:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected Serve to return")
+	}
+}
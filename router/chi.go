@@ -0,0 +1,84 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/cristiangraz/kumi"
+	"github.com/go-chi/chi"
+)
+
+// ChiRouter wraps the chi.Mux router and meets the
+// kumi.Router interface.
+type ChiRouter struct {
+	router *chi.Mux
+	routes []kumi.Route
+}
+
+var (
+	_ kumi.Router      = &ChiRouter{}
+	_ kumi.RouteLister = &ChiRouter{}
+)
+
+// NewChiRouter creates a new instance of a default chi.Mux.
+// If you need to set custom options, you should instantiate ChiRouter
+// yourself.
+func NewChiRouter() *ChiRouter {
+	return &ChiRouter{
+		router: chi.NewRouter(),
+	}
+}
+
+// Handle registers the handler with chi and converts the URL params to
+// Params accessible in the RequestContext.
+func (router *ChiRouter) Handle(method string, pattern string, next http.Handler) {
+	router.routes = append(router.routes, kumi.Route{Method: method, Pattern: pattern})
+	router.router.Method(method, pattern, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && len(rctx.URLParams.Keys) > 0 {
+			p := make(map[string]string, len(rctx.URLParams.Keys))
+			for i, k := range rctx.URLParams.Keys {
+				p[k] = rctx.URLParams.Values[i]
+			}
+			r = kumi.SetParams(r, p)
+		}
+		next.ServeHTTP(w, r)
+	}))
+}
+
+// Routes returns the routes registered with the router.
+func (router *ChiRouter) Routes() []kumi.Route {
+	routes := make([]kumi.Route, len(router.routes))
+	copy(routes, router.routes)
+	return routes
+}
+
+// ServeHTTP calls chi's ServeHTTP method.
+func (router *ChiRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	router.router.ServeHTTP(w, r)
+}
+
+// NotFoundHandler registers a handler to execute when no route is matched.
+func (router *ChiRouter) NotFoundHandler(h http.Handler) {
+	router.router.NotFound(h.ServeHTTP)
+}
+
+// MethodNotAllowedHandler registers a handler to execute when the requested
+// method is not allowed.
+func (router *ChiRouter) MethodNotAllowedHandler(h http.Handler) {
+	router.router.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		methods := make([]string, 0, len(kumi.HTTPMethods))
+		for _, m := range kumi.HTTPMethods {
+			if router.HasRoute(m, r.URL.Path) {
+				methods = append(methods, m)
+			}
+		}
+		w.Header().Set("Allow", strings.Join(methods, ", "))
+		h.ServeHTTP(w, r)
+	})
+}
+
+// HasRoute returns true if the router has registered a route with that
+// method and pattern.
+func (router *ChiRouter) HasRoute(method string, path string) bool {
+	return router.router.Match(chi.NewRouteContext(), method, path)
+}
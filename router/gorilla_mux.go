@@ -13,9 +13,13 @@ import (
 type GorillaMuxRouter struct {
 	router   *mux.Router
 	notFound http.Handler
+	routes   []kumi.Route
 }
 
-var _ kumi.Router = &GorillaMuxRouter{}
+var (
+	_ kumi.Router      = &GorillaMuxRouter{}
+	_ kumi.RouteLister = &GorillaMuxRouter{}
+)
 
 // NewGorillaMuxRouter creates a new instance of a default mux.Router.
 // If you need to set custom options, you should instantiate GorillaMuxRouter
@@ -28,6 +32,7 @@ func NewGorillaMuxRouter() *GorillaMuxRouter {
 
 // Handle ...
 func (router *GorillaMuxRouter) Handle(method string, pattern string, next http.Handler) {
+	router.routes = append(router.routes, kumi.Route{Method: method, Pattern: pattern})
 	router.router.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
 		if p := mux.Vars(r); len(p) > 0 {
 			r = kumi.SetParams(r, p)
@@ -36,6 +41,13 @@ func (router *GorillaMuxRouter) Handle(method string, pattern string, next http.
 	}).Methods(method)
 }
 
+// Routes returns the routes registered with the router.
+func (router *GorillaMuxRouter) Routes() []kumi.Route {
+	routes := make([]kumi.Route, len(router.routes))
+	copy(routes, router.routes)
+	return routes
+}
+
 // ServeHTTP ...
 func (router *GorillaMuxRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	router.router.ServeHTTP(w, r)
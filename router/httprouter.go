@@ -12,9 +12,13 @@ import (
 // kumi.Router interface.
 type HTTPRouter struct {
 	router *httprouter.Router
+	routes []kumi.Route
 }
 
-var _ kumi.Router = &HTTPRouter{}
+var (
+	_ kumi.Router      = &HTTPRouter{}
+	_ kumi.RouteLister = &HTTPRouter{}
+)
 
 // NewHTTPRouter creates a new instance of HTTPRouter.
 func NewHTTPRouter() *HTTPRouter {
@@ -26,6 +30,7 @@ func NewHTTPRouter() *HTTPRouter {
 // Handle implements httprouter.Handler and converts the params to Params accessible
 // in the RequestContext.
 func (router *HTTPRouter) Handle(method string, pattern string, next http.Handler) {
+	router.routes = append(router.routes, kumi.Route{Method: method, Pattern: pattern})
 	router.router.Handle(method, pattern, func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
 		if len(params) > 0 {
 			p := make(map[string]string, len(params))
@@ -39,6 +44,13 @@ func (router *HTTPRouter) Handle(method string, pattern string, next http.Handle
 	})
 }
 
+// Routes returns the routes registered with the router.
+func (router *HTTPRouter) Routes() []kumi.Route {
+	routes := make([]kumi.Route, len(router.routes))
+	copy(routes, router.routes)
+	return routes
+}
+
 // ServeHTTP calls httprouter's ServeHTTP method.
 func (router *HTTPRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	router.router.ServeHTTP(w, r)
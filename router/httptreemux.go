@@ -12,9 +12,13 @@ import (
 // kumi.Router interface.
 type HTTPTreeMux struct {
 	router *httptreemux.TreeMux
+	routes []kumi.Route
 }
 
-var _ kumi.Router = &HTTPTreeMux{}
+var (
+	_ kumi.Router      = &HTTPTreeMux{}
+	_ kumi.RouteLister = &HTTPTreeMux{}
+)
 
 // NewHTTPTreeMux creates a new instance of a default httptreemux router.
 // If you need to set custom options, you should instantiate HTTPTreeMux
@@ -27,6 +31,7 @@ func NewHTTPTreeMux() *HTTPTreeMux {
 
 // Handle ...
 func (router *HTTPTreeMux) Handle(method string, pattern string, next http.Handler) {
+	router.routes = append(router.routes, kumi.Route{Method: method, Pattern: pattern})
 	router.router.Handle(method, pattern, func(w http.ResponseWriter, r *http.Request, p map[string]string) {
 		if len(p) > 0 {
 			r = kumi.SetParams(r, p)
@@ -35,6 +40,13 @@ func (router *HTTPTreeMux) Handle(method string, pattern string, next http.Handl
 	})
 }
 
+// Routes returns the routes registered with the router.
+func (router *HTTPTreeMux) Routes() []kumi.Route {
+	routes := make([]kumi.Route, len(router.routes))
+	copy(routes, router.routes)
+	return routes
+}
+
 // ServeHTTP ...
 func (router *HTTPTreeMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	router.router.ServeHTTP(w, r)
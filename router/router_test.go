@@ -57,6 +57,21 @@ func TestGorilla_NotFoundHandler(t *testing.T) {
 	testRouterNotFoundHandler(t, router.NewGorillaMuxRouter())
 }
 
+func TestChi(t *testing.T) {
+	testRouter(t, routerTest{
+		router: func() kumi.Router {
+			return router.NewChiRouter()
+		},
+		route:  "/users/{name}/{id}",
+		url:    "/users/chi/10",
+		params: kumi.Params{"name": "chi", "id": "10"},
+	})
+}
+
+func TestChi_NotFoundHandler(t *testing.T) {
+	testRouterNotFoundHandler(t, router.NewChiRouter())
+}
+
 type routerTest struct {
 	router     func() kumi.Router
 	route, url string
@@ -166,6 +181,11 @@ func TestMethodNotAllowedHandlers(t *testing.T) {
 			router: router.NewGorillaMuxRouter(),
 			param:  "{id}",
 		},
+		{
+			name:   "chi",
+			router: router.NewChiRouter(),
+			param:  "{id}",
+		},
 	}
 
 	mw := func(next http.Handler) http.Handler {
@@ -238,6 +258,11 @@ func TestHasRoute(t *testing.T) {
 			router: router.NewGorillaMuxRouter(),
 			param:  "{id}",
 		},
+		{
+			name:   "chi",
+			router: router.NewChiRouter(),
+			param:  "{id}",
+		},
 	}
 
 	for _, r := range routers {
@@ -269,3 +294,50 @@ func TestHasRoute(t *testing.T) {
 		}
 	}
 }
+
+func TestRoutes(t *testing.T) {
+	routers := []struct {
+		name   string
+		router kumi.Router
+		param  string
+	}{
+		{
+			name:   "httprouter",
+			router: router.NewHTTPRouter(),
+			param:  ":id",
+		},
+		{
+			name:   "httptreemux",
+			router: router.NewHTTPTreeMux(),
+			param:  ":id",
+		},
+		{
+			name:   "gorilla",
+			router: router.NewGorillaMuxRouter(),
+			param:  "{id}",
+		},
+		{
+			name:   "chi",
+			router: router.NewChiRouter(),
+			param:  "{id}",
+		},
+	}
+
+	for _, r := range routers {
+		k := kumi.New(r.router)
+		k.Get("/users", func(w http.ResponseWriter, r *http.Request) {})
+		k.Post("/users", func(w http.ResponseWriter, r *http.Request) {})
+		k.GroupPath("/users").Get("/"+r.param, func(w http.ResponseWriter, r *http.Request) {})
+
+		expected := []kumi.Route{
+			{Method: "GET", Pattern: "/users"},
+			{Method: "HEAD", Pattern: "/users"},
+			{Method: "POST", Pattern: "/users"},
+			{Method: "GET", Pattern: "/users/" + r.param},
+			{Method: "HEAD", Pattern: "/users/" + r.param},
+		}
+		if routes := k.Routes(); !reflect.DeepEqual(routes, expected) {
+			t.Fatalf("TestRoutes (%s): Expected %#v, given %#v", r.name, expected, routes)
+		}
+	}
+}
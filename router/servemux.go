@@ -0,0 +1,130 @@
+//go:build go1.22
+// +build go1.22
+
+package router
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/cristiangraz/kumi"
+)
+
+// ServeMux wraps the net/http ServeMux router and meets the
+// kumi.Router interface. It requires the method and wildcard
+// pattern matching added in Go 1.22.
+type ServeMux struct {
+	router           *http.ServeMux
+	notFound         http.Handler
+	methodNotAllowed http.Handler
+	routes           []kumi.Route
+}
+
+var (
+	_ kumi.Router      = &ServeMux{}
+	_ kumi.RouteLister = &ServeMux{}
+)
+
+// NewServeMux creates a new instance of ServeMux.
+func NewServeMux() *ServeMux {
+	return &ServeMux{
+		router: http.NewServeMux(),
+	}
+}
+
+// Handle registers the handler for "METHOD pattern" and converts the
+// wildcard path values to Params accessible in the RequestContext.
+func (router *ServeMux) Handle(method string, pattern string, next http.Handler) {
+	router.routes = append(router.routes, kumi.Route{Method: method, Pattern: pattern})
+	names := wildcardNames(pattern)
+	router.router.Handle(method+" "+pattern, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(names) > 0 {
+			p := make(map[string]string, len(names))
+			for _, name := range names {
+				p[name] = r.PathValue(name)
+			}
+			r = kumi.SetParams(r, p)
+		}
+		next.ServeHTTP(w, r)
+	}))
+}
+
+// Routes returns the routes registered with the router.
+func (router *ServeMux) Routes() []kumi.Route {
+	routes := make([]kumi.Route, len(router.routes))
+	copy(routes, router.routes)
+	return routes
+}
+
+// ServeHTTP runs the NotFound or MethodNotAllowed handlers if the request
+// does not match a pattern and those handlers are set. Otherwise the
+// request is passed to the ServeMux.
+func (router *ServeMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if router.notFound == nil && router.methodNotAllowed == nil {
+		router.router.ServeHTTP(w, r)
+		return
+	}
+
+	if _, pattern := router.router.Handler(r); pattern == "" {
+		methods := router.getMethods(r.URL.Path)
+		if len(methods) > 0 && router.methodNotAllowed != nil {
+			w.Header().Set("Allow", strings.Join(methods, ", "))
+			router.methodNotAllowed.ServeHTTP(w, r)
+			return
+		} else if len(methods) == 0 && router.notFound != nil {
+			router.notFound.ServeHTTP(w, r)
+			return
+		}
+	}
+	router.router.ServeHTTP(w, r)
+}
+
+// NotFoundHandler registers a handler to execute when no route is matched.
+func (router *ServeMux) NotFoundHandler(h http.Handler) {
+	router.notFound = h
+}
+
+// MethodNotAllowedHandler registers a handler to execute when the requested
+// method is not allowed.
+func (router *ServeMux) MethodNotAllowedHandler(h http.Handler) {
+	router.methodNotAllowed = h
+}
+
+// HasRoute returns true if the router has registered a route with that
+// method and pattern.
+func (router *ServeMux) HasRoute(method string, path string) bool {
+	req, err := http.NewRequest(method, path, nil)
+	if err != nil {
+		return false
+	}
+	_, pattern := router.router.Handler(req)
+	return pattern != ""
+}
+
+// getMethods returns the methods with a route matching path.
+func (router *ServeMux) getMethods(path string) (methods []string) {
+	for _, m := range kumi.HTTPMethods {
+		if router.HasRoute(m, path) {
+			methods = append(methods, m)
+		}
+	}
+	return methods
+}
+
+// wildcardNames returns the names of the wildcards in a ServeMux pattern
+// (i.e. "id" and "path" for /users/{id}/files/{path...}).
+func wildcardNames(pattern string) []string {
+	var names []string
+	for _, seg := range strings.Split(pattern, "/") {
+		if len(seg) < 3 || seg[0] != '{' || seg[len(seg)-1] != '}' {
+			continue
+		}
+
+		name := strings.TrimSuffix(seg[1:len(seg)-1], "...")
+		if name == "$" {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,91 @@
+//go:build go1.22
+// +build go1.22
+
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/cristiangraz/kumi"
+	"github.com/cristiangraz/kumi/router"
+)
+
+func TestServeMux(t *testing.T) {
+	testRouter(t, routerTest{
+		router: func() kumi.Router {
+			return router.NewServeMux()
+		},
+		route:  "/users/{name}/{id}",
+		url:    "/users/servemux/10",
+		params: kumi.Params{"name": "servemux", "id": "10"},
+	})
+}
+
+func TestServeMux_NotFoundHandler(t *testing.T) {
+	testRouterNotFoundHandler(t, router.NewServeMux())
+}
+
+func TestServeMux_MethodNotAllowedHandler(t *testing.T) {
+	k := kumi.New(router.NewServeMux())
+	k.Patch("/path/{id}", func(w http.ResponseWriter, r *http.Request) {})
+	k.Delete("/path/{id}", func(w http.ResponseWriter, r *http.Request) {})
+	k.MethodNotAllowedHandler(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Method-Not-Allowed-Handler", "True")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+
+	req, _ := http.NewRequest("GET", "/path/10", nil)
+	w := httptest.NewRecorder()
+	k.ServeHTTP(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	} else if w.Header().Get("X-Method-Not-Allowed-Handler") != "True" {
+		t.Fatal("expected X-Method-Not-Allowed-Handler header")
+	}
+
+	a := strings.Split(w.Header().Get("Allow"), ", ")
+	sort.Strings(a)
+	if !reflect.DeepEqual(a, []string{"DELETE", "PATCH"}) {
+		t.Fatalf("unexpected methods: %#v", a)
+	}
+}
+
+func TestServeMux_HasRoute(t *testing.T) {
+	k := kumi.New(router.NewServeMux())
+	k.Get("/", func(w http.ResponseWriter, r *http.Request) {})
+	k.Post("/bla/bla", func(w http.ResponseWriter, r *http.Request) {})
+	k.Patch("/path/{id}", func(w http.ResponseWriter, r *http.Request) {})
+
+	if !k.HasRoute("GET", "/") {
+		t.Fatal("expected route to be found")
+	} else if !k.HasRoute("HEAD", "/") {
+		t.Fatal("expected route to be found")
+	} else if !k.HasRoute("POST", "/bla/bla") {
+		t.Fatal("expected route to be found")
+	} else if !k.HasRoute("PATCH", "/path/10") {
+		t.Fatal("expected route to be found")
+	} else if k.HasRoute("DELETE", "/path/10") {
+		t.Fatal("expected route not to be found")
+	}
+}
+
+func TestServeMux_Routes(t *testing.T) {
+	k := kumi.New(router.NewServeMux())
+	k.Get("/users", func(w http.ResponseWriter, r *http.Request) {})
+	k.Post("/users", func(w http.ResponseWriter, r *http.Request) {})
+
+	expected := []kumi.Route{
+		{Method: "GET", Pattern: "/users"},
+		{Method: "HEAD", Pattern: "/users"},
+		{Method: "POST", Pattern: "/users"},
+	}
+	if routes := k.Routes(); !reflect.DeepEqual(routes, expected) {
+		t.Fatalf("unexpected routes: %#v", routes)
+	}
+}
@@ -2,8 +2,11 @@ package kumi
 
 import (
 	"net/http"
+	"sort"
+	"strings"
 
 	"github.com/justinas/alice"
+	"github.com/xeipuuv/gojsonschema"
 )
 
 // HTTPMethods is a list of HTTP methods kumi supports.
@@ -18,6 +21,8 @@ const (
 	PATCH   = "PATCH"
 	DELETE  = "DELETE"
 	OPTIONS = "OPTIONS"
+	TRACE   = "TRACE"
+	CONNECT = "CONNECT"
 )
 
 // RouteChecker checks to see if the router has a matching route
@@ -40,6 +45,24 @@ type Router interface {
 	MethodNotAllowedHandler(http.Handler)
 }
 
+// Route describes a route registered with a Router, or a route to
+// register with RouterGroup.Register.
+type Route struct {
+	Method  string
+	Pattern string
+
+	// Handler and Middleware are used by Register. They are not set on
+	// the routes returned by Routes.
+	Handler    http.HandlerFunc
+	Middleware []func(http.Handler) http.Handler
+}
+
+// RouteLister is optionally implemented by a Router to enumerate the
+// routes registered with it.
+type RouteLister interface {
+	Routes() []Route
+}
+
 // RouterGroup wraps the Router interface to provide route grouping by
 // a base pattern path and shared middleware.
 type RouterGroup interface {
@@ -87,6 +110,19 @@ type RouterGroup interface {
 	// HTTP method requests at pattern.
 	All(pattern string, handler http.HandlerFunc)
 
+	// GetNoHead defines a handler for a GET request at pattern without
+	// the HEAD route kumi registers automatically for Get.
+	GetNoHead(pattern string, handler http.HandlerFunc)
+
+	// GetCacheable defines a handler for a GET request at pattern whose
+	// Cache-Control header is set from policy based on the response status.
+	GetCacheable(pattern string, policy CachePolicy, handler http.HandlerFunc)
+
+	// GetWithSchema defines a handler for a GET request at pattern and
+	// records outSchema as the route's response schema. See
+	// Engine.ResponseSchemas.
+	GetWithSchema(pattern string, outSchema gojsonschema.JSONLoader, handler http.HandlerFunc)
+
 	// NotFoundHandler registers a handler to run when no matching route is found.
 	NotFoundHandler(http.HandlerFunc)
 
@@ -94,10 +130,24 @@ type RouterGroup interface {
 	// but not for the requested HTTP method.
 	MethodNotAllowedHandler(http.HandlerFunc)
 
+	// Register defines each route in routes. Each route's Middleware is
+	// appended to the group's middleware for that route only.
+	Register(routes []Route)
+
 	// AutoOptionsMethod enables functionality so that all routes are
 	// automatically created with an OPTIONS route.
 	AutoOptionsMethod()
 
+	// AutoOptions enables functionality so that all routes are
+	// automatically created with an OPTIONS route that responds with a
+	// 204 No Content and an Allow header.
+	AutoOptions()
+
+	// Routes returns the routes registered with the Router in the order
+	// they were registered, including the automatic HEAD and OPTIONS routes.
+	// It returns nil if the Router does not implement RouteLister.
+	Routes() []Route
+
 	// ServeHTTP implements the http.Handler interface.
 	ServeHTTP(http.ResponseWriter, *http.Request)
 }
@@ -108,6 +158,21 @@ type routerGroup struct {
 	router            Router
 	middleware        alice.Chain
 	autoOptionsMethod bool
+	autoOptions       bool
+
+	// schemas holds the response schemas registered with GetWithSchema,
+	// keyed by pattern. It is shared by every group of an Engine.
+	schemas map[string]gojsonschema.JSONLoader
+
+	// registered records the method and pattern of every route added
+	// through the groups of an Engine. Some routers report a HEAD route
+	// wherever a GET route exists, so HasRoute cannot tell whether HEAD
+	// still needs registering.
+	registered map[string]bool
+
+	// schema is the response schema for the route being registered by
+	// GetWithSchema.
+	schema gojsonschema.JSONLoader
 }
 
 var _ RouterGroup = &routerGroup{}
@@ -124,6 +189,9 @@ func (g *routerGroup) Group(middleware ...func(http.Handler) http.Handler) Route
 		router:            g.router,
 		middleware:        g.middleware.Append(c...),
 		autoOptionsMethod: g.autoOptionsMethod,
+		autoOptions:       g.autoOptions,
+		schemas:           g.schemas,
+		registered:        g.registered,
 	}
 }
 
@@ -140,6 +208,9 @@ func (g *routerGroup) GroupPath(pattern string, middleware ...func(http.Handler)
 		router:            g.router,
 		middleware:        g.middleware.Append(c...),
 		autoOptionsMethod: g.autoOptionsMethod,
+		autoOptions:       g.autoOptions,
+		schemas:           g.schemas,
+		registered:        g.registered,
 	}
 }
 
@@ -160,6 +231,13 @@ func (g *routerGroup) Get(pattern string, handler http.HandlerFunc) {
 	g.handle(GET, pattern, handler)
 }
 
+// GetNoHead defines an HTTP GET endpoint without registering the
+// matching HEAD endpoint, i.e. for GET routes whose side effects or
+// generated bodies should not be repeated for HEAD requests.
+func (g *routerGroup) GetNoHead(pattern string, handler http.HandlerFunc) {
+	g.register(GET, pattern, handler, false)
+}
+
 // Post defines an HTTP POST endpoint with one or more handlers.
 func (g *routerGroup) Post(pattern string, handler http.HandlerFunc) {
 	g.handle(POST, pattern, handler)
@@ -201,13 +279,60 @@ func (g *routerGroup) Delete(pattern string, handler http.HandlerFunc) {
 // Note HEAD/OPTIONS are set in the handle method automatically.
 func (g *routerGroup) All(pattern string, handler http.HandlerFunc) {
 	for _, method := range HTTPMethods {
-		if g.autoOptionsMethod && method == OPTIONS {
+		if (g.autoOptionsMethod || g.autoOptions) && method == OPTIONS {
 			continue
 		}
 		g.handle(method, pattern, handler)
 	}
 }
 
+// Register defines each route in routes, in order. Each route's
+// Middleware runs after the group's middleware and only for that route.
+func (g *routerGroup) Register(routes []Route) {
+	for _, route := range routes {
+		if len(route.Middleware) == 0 {
+			g.handle(route.Method, route.Pattern, route.Handler)
+			continue
+		}
+
+		c := make([]alice.Constructor, len(route.Middleware))
+		for i := range route.Middleware {
+			c[i] = alice.Constructor(route.Middleware[i])
+		}
+
+		rg := *g
+		rg.middleware = g.middleware.Append(c...)
+		rg.handle(route.Method, route.Pattern, route.Handler)
+	}
+}
+
+// GetCacheable defines an HTTP GET endpoint, and the matching HEAD
+// endpoint, whose Cache-Control header is set from policy when the
+// response status is written.
+func (g *routerGroup) GetCacheable(pattern string, policy CachePolicy, handler http.HandlerFunc) {
+	if handler == nil {
+		panic("cannot send a nil http.HandlerFunc")
+	}
+	g.handle(GET, pattern, policy.handler(handler))
+}
+
+// GetWithSchema defines an HTTP GET endpoint, and the matching HEAD
+// endpoint, and records outSchema as its response schema. The schema
+// documents the route's contract and is not validated at runtime unless
+// the ResponseSchema middleware is used.
+func (g *routerGroup) GetWithSchema(pattern string, outSchema gojsonschema.JSONLoader, handler http.HandlerFunc) {
+	if outSchema == nil {
+		panic("cannot send a nil response schema")
+	}
+
+	rg := *g
+	rg.schema = outSchema
+	rg.handle(GET, pattern, handler)
+	if g.schemas != nil {
+		g.schemas[g.pattern+pattern] = outSchema
+	}
+}
+
 // NotFoundHandler runs when no route is found.
 // inhermitMiddleware determines if the global and group middleware chain
 // should run on a not found request. You can optionally set to false and
@@ -231,12 +356,49 @@ func (g *routerGroup) AutoOptionsMethod() {
 	g.autoOptionsMethod = true
 }
 
+// AutoOptions enables functionality so that all routes are automatically
+// created with an OPTIONS route that responds with a 204 No Content and
+// an Allow header listing the methods defined for the path. The route
+// runs the group's middleware, so Cors still handles CORS requests.
+// AutoOptions takes precedence over AutoOptionsMethod.
+func (g *routerGroup) AutoOptions() {
+	g.autoOptions = true
+}
+
+// allowOptions responds to an OPTIONS request with the methods allowed
+// for the path.
+func (g *routerGroup) allowOptions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Allow", strings.Join(allowedMethods(g.router, r.URL.Path), ", "))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// allowedMethods returns the sorted HTTPMethods with a route at path.
+func allowedMethods(c RouteChecker, path string) []string {
+	methods := make([]string, 0, len(HTTPMethods))
+	for _, m := range HTTPMethods {
+		if c.HasRoute(m, path) {
+			methods = append(methods, m)
+		}
+	}
+	sort.Strings(methods)
+	return methods
+}
+
 // HasRoute checks to see if the router has a matching route
 // for that method and path.
 func (g *routerGroup) HasRoute(method string, path string) bool {
 	return g.router.HasRoute(method, path)
 }
 
+// Routes returns the routes registered with the Router if it
+// implements RouteLister.
+func (g *routerGroup) Routes() []Route {
+	if l, ok := g.router.(RouteLister); ok {
+		return l.Routes()
+	}
+	return nil
+}
+
 // ServeHTTP ...
 func (g *routerGroup) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	g.router.ServeHTTP(w, r)
@@ -245,23 +407,42 @@ func (g *routerGroup) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 // handle consolidates all of the middleware into a route that satisfies the
 // router.Handle interface
 func (g *routerGroup) handle(method, pattern string, handler http.HandlerFunc) {
+	g.register(method, pattern, handler, true)
+}
+
+// register defines the route. If autoHead is true a HEAD route is added
+// for GET routes.
+func (g *routerGroup) register(method, pattern string, handler http.HandlerFunc, autoHead bool) {
 	if handler == nil {
 		panic("cannot send a nil http.HandlerFunc")
 	}
 
-	h := g.middleware.ThenFunc(handler)
 	pattern = g.pattern + pattern
+	rt := &route{pattern: pattern, schema: g.schema}
+	h := withRoute(rt, g.middleware.ThenFunc(handler))
 
-	g.router.Handle(method, pattern, h)
+	g.handleRoute(method, pattern, h)
 
 	// Add HEAD to all GET routes if no route is already defined.
-	if method == GET && !g.router.HasRoute(HEAD, pattern) {
-		g.router.Handle(HEAD, pattern, h)
+	if autoHead && method == GET && !g.registered[HEAD+" "+pattern] {
+		g.handleRoute(HEAD, pattern, h)
+	}
+
+	// Add OPTIONS to all routes if no route is already defined.
+	if method != OPTIONS && !g.router.HasRoute(OPTIONS, pattern) {
+		if g.autoOptions {
+			g.handleRoute(OPTIONS, pattern, withRoute(&route{pattern: pattern}, g.middleware.ThenFunc(g.allowOptions)))
+		} else if g.autoOptionsMethod {
+			g.handleRoute(OPTIONS, pattern, h)
+		}
 	}
+}
 
-	// Add OPTIONS to all CORS routes if no route is already defined.
-	if g.autoOptionsMethod && method != OPTIONS && !g.router.HasRoute(OPTIONS, pattern) {
-		g.router.Handle(OPTIONS, pattern, h)
+// handleRoute adds the route to the router and records it as registered.
+func (g *routerGroup) handleRoute(method, pattern string, h http.Handler) {
+	g.router.Handle(method, pattern, h)
+	if g.registered != nil {
+		g.registered[method+" "+pattern] = true
 	}
 }
 
@@ -4,10 +4,12 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 
 	"github.com/cristiangraz/kumi"
+	"github.com/xeipuuv/gojsonschema"
 )
 
 func TestRouterGroup_ResponseWriterSet(t *testing.T) {
@@ -316,6 +318,90 @@ func TestRouterGroup_Cors_GroupPath(t *testing.T) {
 	}
 }
 
+func TestRouterGroup_AutoOptions(t *testing.T) {
+	var ran bool
+	k := kumi.New(&Router{})
+	k.AutoOptions()
+	k.Delete("/users", func(w http.ResponseWriter, r *http.Request) {
+		ran = true
+	})
+	k.GroupPath("/a").Get("/b", func(w http.ResponseWriter, r *http.Request) {
+		ran = true
+	})
+
+	tests := []struct {
+		path  string
+		allow string
+	}{
+		{path: "/users", allow: "DELETE, OPTIONS"},
+		{path: "/a/b", allow: "GET, HEAD, OPTIONS"},
+	}
+
+	for i, tt := range tests {
+		r, _ := http.NewRequest("OPTIONS", tt.path, nil)
+		w := httptest.NewRecorder()
+		k.ServeHTTP(w, r)
+
+		if ran {
+			t.Fatalf("TestRouterGroup_AutoOptions (%d): Expected handler not to run", i)
+		} else if w.Code != http.StatusNoContent {
+			t.Fatalf("TestRouterGroup_AutoOptions (%d): Expected status code %d, given %d", i, http.StatusNoContent, w.Code)
+		} else if allow := w.Header().Get("Allow"); allow != tt.allow {
+			t.Fatalf("TestRouterGroup_AutoOptions (%d): Expected Allow %q, given %q", i, tt.allow, allow)
+		}
+	}
+}
+
+func TestRouterGroup_Register(t *testing.T) {
+	k := kumi.New(&Router{})
+	k.Use(tagMiddleware("a"))
+	g := k.GroupPath("/v1")
+	g.Register([]kumi.Route{
+		{
+			Method:  kumi.GET,
+			Pattern: "/users",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("list"))
+			},
+		},
+		{
+			Method:     kumi.POST,
+			Pattern:    "/users",
+			Middleware: []func(http.Handler) http.Handler{tagMiddleware("b"), tagMiddleware("c")},
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("create"))
+			},
+		},
+		{
+			Method:     kumi.DELETE,
+			Pattern:    "/users",
+			Middleware: []func(http.Handler) http.Handler{tagHaltMiddleware("d")},
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("delete"))
+			},
+		},
+	})
+
+	tests := []struct {
+		method string
+		body   string
+	}{
+		{method: "GET", body: "alistA"},
+		{method: "POST", body: "abccreateCBA"},
+		{method: "DELETE", body: "adA"},
+	}
+
+	for i, tt := range tests {
+		r, _ := http.NewRequest(tt.method, "/v1/users", nil)
+		w := httptest.NewRecorder()
+		k.ServeHTTP(w, r)
+
+		if w.Body.String() != tt.body {
+			t.Fatalf("TestRouterGroup_Register (%d): Expected %q, given %q", i, tt.body, w.Body.String())
+		}
+	}
+}
+
 func TestRouterGroup_HeadRequestUseBodylessWriter(t *testing.T) {
 	var ran bool
 	k := kumi.New(&Router{})
@@ -468,6 +554,15 @@ func TestRouterGroup_MethodNotAllowedHandler(t *testing.T) {
 	}
 }
 
+func TestRouterGroup_RoutesNotSupported(t *testing.T) {
+	k := kumi.New(&Router{})
+	k.Get("/", func(w http.ResponseWriter, r *http.Request) {})
+
+	if routes := k.Routes(); routes != nil {
+		t.Fatalf("unexpected routes: %#v", routes)
+	}
+}
+
 // Router used for testing.
 type Router struct {
 	routes           map[string]map[string]http.Handler
@@ -565,3 +660,49 @@ func quietMiddleware(tag string) func(http.Handler) http.Handler {
 		})
 	}
 }
+
+func TestRouterGroup_GetWithSchema(t *testing.T) {
+	users := gojsonschema.NewStringLoader(`{"type": "object"}`)
+	user := gojsonschema.NewStringLoader(`{"type": "object", "required": ["id"]}`)
+
+	k := kumi.New(&Router{})
+	k.GetWithSchema("/users", users, func(w http.ResponseWriter, r *http.Request) {})
+	k.GroupPath("/users").GetWithSchema("/:id", user, func(w http.ResponseWriter, r *http.Request) {})
+	k.Get("/health", func(w http.ResponseWriter, r *http.Request) {})
+
+	schemas := k.ResponseSchemas()
+	if len(schemas) != 2 {
+		t.Fatalf("TestRouterGroup_GetWithSchema: Expected 2 schemas, given %d", len(schemas))
+	} else if !reflect.DeepEqual(schemas["/users"], users) {
+		t.Errorf("TestRouterGroup_GetWithSchema: Expected schema for /users, given %v", schemas["/users"])
+	} else if !reflect.DeepEqual(schemas["/users/:id"], user) {
+		t.Errorf("TestRouterGroup_GetWithSchema: Expected schema for /users/:id, given %v", schemas["/users/:id"])
+	}
+
+	if !k.HasRoute(kumi.GET, "/users/:id") || !k.HasRoute(kumi.HEAD, "/users/:id") {
+		t.Error("TestRouterGroup_GetWithSchema: Expected GET and HEAD routes to be registered")
+	}
+
+	// The returned map is a copy.
+	delete(schemas, "/users")
+	if _, ok := k.ResponseSchemas()["/users"]; !ok {
+		t.Error("TestRouterGroup_GetWithSchema: Expected ResponseSchemas to return a copy")
+	}
+}
+
+func TestRouterGroup_GetNoHead(t *testing.T) {
+	k := kumi.New(&Router{})
+	k.AutoOptions()
+	k.GetNoHead("/export", func(w http.ResponseWriter, r *http.Request) {})
+	k.Get("/users", func(w http.ResponseWriter, r *http.Request) {})
+
+	if !k.HasRoute(kumi.GET, "/export") {
+		t.Fatal("TestRouterGroup_GetNoHead: Expected GET route to be registered")
+	} else if k.HasRoute(kumi.HEAD, "/export") {
+		t.Fatal("TestRouterGroup_GetNoHead: Expected HEAD route not to be registered")
+	} else if !k.HasRoute(kumi.OPTIONS, "/export") {
+		t.Fatal("TestRouterGroup_GetNoHead: Expected OPTIONS route to be registered")
+	} else if !k.HasRoute(kumi.HEAD, "/users") {
+		t.Fatal("TestRouterGroup_GetNoHead: Expected HEAD route to be registered for Get")
+	}
+}
@@ -0,0 +1,101 @@
+package kumi
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+// SmokeResult is the result of requesting a single route in SmokeTest.
+type SmokeResult struct {
+	Route
+
+	// Path is the request path with the route params filled in.
+	Path string
+
+	// Status is the response status code, or 0 if the route could
+	// not be requested.
+	Status int
+
+	// Err is set if the route could not be requested or the
+	// handler panicked.
+	Err error
+}
+
+// Failed returns true if the route could not be requested, panicked, or
+// responded with a 5xx status code.
+func (r SmokeResult) Failed() bool {
+	return r.Err != nil || r.Status >= 500
+}
+
+// SmokeTest issues an in-process GET request to every GET route registered
+// with the Engine and records the response status. Route params are filled
+// from seedParams by name; routes with a param missing from seedParams are
+// not requested and have Err set. The Engine's router must implement
+// RouteLister, otherwise no routes are tested.
+//
+// SmokeTest is intended for CI checks that catch obvious wiring errors.
+func SmokeTest(e *Engine, seedParams map[string]string) []SmokeResult {
+	var results []SmokeResult
+	for _, route := range e.Routes() {
+		if route.Method != GET {
+			continue
+		}
+
+		result := SmokeResult{Route: route}
+		result.Path, result.Err = fillPattern(route.Pattern, seedParams)
+		if result.Err == nil {
+			result.Status, result.Err = smokeRequest(e, result.Path)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// smokeRequest requests path and returns the response status. A panic in
+// the handler is returned as an error with a 500 status.
+func smokeRequest(h http.Handler, path string) (status int, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			status, err = http.StatusInternalServerError, fmt.Errorf("smoke: panic: %v", v)
+		}
+	}()
+
+	r, err := http.NewRequest(GET, path, nil)
+	if err != nil {
+		return 0, err
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	return w.Code, nil
+}
+
+// fillPattern replaces the params in a route pattern with values from
+// params. It understands the param syntax of the included routers:
+// :name, *name, {name}, {name:regexp}, and {name...}.
+func fillPattern(pattern string, params map[string]string) (string, error) {
+	segments := strings.Split(pattern, "/")
+	for i, s := range segments {
+		var name string
+		switch {
+		case strings.HasPrefix(s, ":"), strings.HasPrefix(s, "*"):
+			name = s[1:]
+		case strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}"):
+			name = strings.TrimSuffix(s[1:len(s)-1], "...")
+			if n := strings.Index(name, ":"); n >= 0 {
+				name = name[:n]
+			}
+		default:
+			continue
+		}
+
+		v, ok := params[name]
+		if !ok {
+			return "", fmt.Errorf("smoke: no seed value for param %q", name)
+		}
+		segments[i] = v
+	}
+	return strings.Join(segments, "/"), nil
+}
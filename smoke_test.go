@@ -0,0 +1,61 @@
+package kumi_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/cristiangraz/kumi"
+	"github.com/cristiangraz/kumi/router"
+)
+
+func TestSmokeTest(t *testing.T) {
+	k := kumi.New(router.NewHTTPRouter())
+	k.Get("/", func(w http.ResponseWriter, r *http.Request) {})
+	k.Get("/users/:id", func(w http.ResponseWriter, r *http.Request) {
+		if kumi.Context(r).Params().Get("id") != "1" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	k.Get("/orgs/:org/teams/:team", func(w http.ResponseWriter, r *http.Request) {})
+	k.Get("/broken", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	k.Get("/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+	k.Post("/users", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	results := kumi.SmokeTest(k, map[string]string{"id": "1", "org": "acme"})
+
+	tests := []struct {
+		pattern string
+		path    string
+		status  int
+		failed  bool
+	}{
+		{pattern: "/", path: "/", status: http.StatusOK},
+		{pattern: "/users/:id", path: "/users/1", status: http.StatusOK},
+		{pattern: "/orgs/:org/teams/:team", failed: true},
+		{pattern: "/broken", path: "/broken", status: http.StatusInternalServerError, failed: true},
+		{pattern: "/panic", path: "/panic", status: http.StatusInternalServerError, failed: true},
+	}
+
+	if len(results) != len(tests) {
+		t.Fatalf("TestSmokeTest: Expected %d results, given %d: %#v", len(tests), len(results), results)
+	}
+
+	for i, tt := range tests {
+		result := results[i]
+		if result.Method != "GET" || result.Pattern != tt.pattern {
+			t.Fatalf("TestSmokeTest (%d): Expected GET %s, given %s %s", i, tt.pattern, result.Method, result.Pattern)
+		} else if result.Path != tt.path {
+			t.Fatalf("TestSmokeTest (%d): Expected path %q, given %q", i, tt.path, result.Path)
+		} else if result.Status != tt.status {
+			t.Fatalf("TestSmokeTest (%d): Expected status %d, given %d", i, tt.status, result.Status)
+		} else if result.Failed() != tt.failed {
+			t.Fatalf("TestSmokeTest (%d): Expected failed %v, given %v (%v)", i, tt.failed, result.Failed(), result.Err)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package kumi
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Span is a named duration recorded with RequestContext.StartSpan.
+type Span struct {
+	Name     string
+	Duration time.Duration
+}
+
+// ServerTiming formats spans as a Server-Timing header value, with each
+// duration in milliseconds (i.e. "db;dur=12.5, render;dur=3"). Span names
+// should be valid header tokens.
+func ServerTiming(spans []Span) string {
+	metrics := make([]string, len(spans))
+	for i, s := range spans {
+		ms := float64(s.Duration) / float64(time.Millisecond)
+		metrics[i] = s.Name + ";dur=" + strconv.FormatFloat(ms, 'f', -1, 64)
+	}
+	return strings.Join(metrics, ", ")
+}
+
+// SetServerTiming sets the Server-Timing header from the spans recorded
+// for the request. It must be called before the response is written.
+func SetServerTiming(w http.ResponseWriter, r *http.Request) {
+	if spans := Context(r).Spans(); len(spans) > 0 {
+		w.Header().Set("Server-Timing", ServerTiming(spans))
+	}
+}
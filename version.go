@@ -0,0 +1,26 @@
+package kumi
+
+import (
+	"encoding/xml"
+	"net/http"
+
+	"github.com/cristiangraz/kumi/api"
+)
+
+// VersionInfo holds build metadata served by VersionEndpoint.
+type VersionInfo struct {
+	XMLName xml.Name `xml:"version" json:"-"`
+
+	Version   string `json:"version" xml:"version"`
+	Commit    string `json:"commit" xml:"commit"`
+	BuildTime string `json:"build_time" xml:"build_time"`
+}
+
+// VersionEndpoint registers a GET route at path that responds with info
+// as an api.Success response. The response is never cached.
+func (e *Engine) VersionEndpoint(path string, info VersionInfo) {
+	e.Get(path, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		api.Success(info).Send(w)
+	})
+}
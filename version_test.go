@@ -0,0 +1,32 @@
+package kumi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cristiangraz/kumi"
+)
+
+func TestEngine_VersionEndpoint(t *testing.T) {
+	k := kumi.New(&Router{})
+	k.VersionEndpoint("/version", kumi.VersionInfo{
+		Version:   "1.2.0",
+		Commit:    "a1b2c3d",
+		BuildTime: "2024-01-02T15:04:05Z",
+	})
+
+	r, _ := http.NewRequest("GET", "/version", nil)
+	w := httptest.NewRecorder()
+	k.ServeHTTP(w, r)
+
+	expected := `{"success":true,"result":{"version":"1.2.0","commit":"a1b2c3d","build_time":"2024-01-02T15:04:05Z"}}`
+	if w.Code != http.StatusOK {
+		t.Fatalf("TestEngine_VersionEndpoint: Expected status %d, given %d", http.StatusOK, w.Code)
+	} else if given := w.Header().Get("Cache-Control"); given != "no-store" {
+		t.Fatalf("TestEngine_VersionEndpoint: Expected Cache-Control no-store, given %q", given)
+	} else if given := strings.TrimSpace(w.Body.String()); given != expected {
+		t.Fatalf("TestEngine_VersionEndpoint: Expected %s, given %s", expected, given)
+	}
+}
@@ -3,6 +3,8 @@ package kumi
 import (
 	"bufio"
 	"errors"
+	"io"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"sync"
@@ -17,6 +19,10 @@ type ResponseWriter interface {
 
 	// Written returns the number of bytes written.
 	Written() int
+
+	// HeaderWritten returns true once the status code has been written.
+	// Middleware can check it to avoid a superfluous WriteHeader call.
+	HeaderWritten() bool
 }
 
 type responseWriter struct {
@@ -34,9 +40,10 @@ type responseWriter struct {
 
 var _ ResponseWriter = &responseWriter{}
 
-// WriteHeader prepares the response once. If a 204 No Content response
-// is being sent, or the BodylessResponseWriter is in use,
-// no Content-Type header will be sent.
+// WriteHeader prepares the response once. If a 204 No Content or
+// 304 Not Modified response is being sent, or the BodylessResponseWriter
+// is in use, no Content-Type header or body will be sent. Calls after the
+// header has been written are ignored.
 func (w *responseWriter) WriteHeader(s int) {
 	if w.wroteHeader {
 		return
@@ -44,7 +51,7 @@ func (w *responseWriter) WriteHeader(s int) {
 	w.wroteHeader = true
 	w.status = s
 
-	if s == http.StatusNoContent {
+	if s == http.StatusNoContent || s == http.StatusNotModified {
 		w.ResponseWriter = &BodylessResponseWriter{ResponseWriter: w.ResponseWriter}
 	}
 
@@ -65,6 +72,29 @@ func (w *responseWriter) Write(p []byte) (int, error) {
 	return n, err
 }
 
+// ReadFrom implements the io.ReaderFrom interface so io.Copy and
+// http.ServeContent can use the underlying writer's ReadFrom, such as
+// net/http's sendfile support.
+func (w *responseWriter) ReadFrom(r io.Reader) (int64, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	switch rw := w.ResponseWriter.(type) {
+	case *BodylessResponseWriter:
+		return io.Copy(ioutil.Discard, r)
+	case io.ReaderFrom:
+		n, err := rw.ReadFrom(r)
+		w.n += int(n)
+		return n, err
+	}
+
+	// Hide ReadFrom so io.Copy does not call it again.
+	n, err := io.Copy(struct{ io.Writer }{w.ResponseWriter}, r)
+	w.n += int(n)
+	return n, err
+}
+
 // Status returns the status code for the response.
 func (w *responseWriter) Status() int {
 	return w.status
@@ -75,6 +105,11 @@ func (w *responseWriter) Written() int {
 	return w.n
 }
 
+// HeaderWritten returns true once the status code has been written.
+func (w *responseWriter) HeaderWritten() bool {
+	return w.wroteHeader
+}
+
 // Hijack implements the http.Hijacker interface.
 func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	h, ok := w.ResponseWriter.(http.Hijacker)
@@ -96,6 +131,15 @@ func (w *responseWriter) Flush() {
 	}
 }
 
+// Push implements the http.Pusher interface. http.ErrNotSupported is
+// returned if the underlying writer does not support server push.
+func (w *responseWriter) Push(target string, opts *http.PushOptions) error {
+	if p, ok := w.ResponseWriter.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
 var _ ResponseWriter = &BodylessResponseWriter{}
 
 // BodylessResponseWriter wraps http.ResponseWriter, discarding
@@ -139,6 +183,11 @@ func (w *BodylessResponseWriter) Written() int {
 	return 0
 }
 
+// HeaderWritten returns true once the status code has been written.
+func (w *BodylessResponseWriter) HeaderWritten() bool {
+	return w.wroteHeader
+}
+
 var writerPool = &sync.Pool{
 	New: func() interface{} {
 		return &responseWriter{}
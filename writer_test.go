@@ -1,8 +1,10 @@
 package kumi_test
 
 import (
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/cristiangraz/kumi"
@@ -101,6 +103,29 @@ func TestWriter_NoContentUsesBodylessWriter(t *testing.T) {
 	}
 }
 
+// A 304 Not Modified should not write a body or send a Content-Type header.
+func TestWriter_NotModifiedUsesBodylessWriter(t *testing.T) {
+	k := kumi.New(&Router{})
+
+	k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotModified)
+		w.Write([]byte("writing content"))
+	})
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	k.ServeHTTP(w, r)
+
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	} else if w.Body.Len() > 0 {
+		t.Fatalf("expected no response body: %s", w.Body.String())
+	} else if ct := w.Header().Get("Content-Type"); ct != "" {
+		t.Fatalf("unexpected content-type: %s", ct)
+	}
+}
+
 func TestWriter_BodylessResponseWriter_Written(t *testing.T) {
 	k := kumi.New(&Router{})
 
@@ -188,3 +213,161 @@ func TestBodyLessResponseWriter_Write(t *testing.T) {
 		t.Fatalf("expected no bytes to be written: %s", w.Body.String())
 	}
 }
+
+// countingWriter counts calls to WriteHeader on the underlying writer.
+type countingWriter struct {
+	*httptest.ResponseRecorder
+	calls int
+}
+
+func (w *countingWriter) WriteHeader(s int) {
+	w.calls++
+	w.ResponseRecorder.WriteHeader(s)
+}
+
+func TestWriter_WriteHeaderOnce(t *testing.T) {
+	tests := []struct {
+		handler http.HandlerFunc
+		status  int
+	}{
+		{ // double WriteHeader
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusCreated)
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			status: http.StatusCreated,
+		},
+		{ // Write then WriteHeader
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("body"))
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			status: http.StatusOK,
+		},
+		{ // WriteHeader with no body
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusAccepted)
+			},
+			status: http.StatusAccepted,
+		},
+	}
+
+	for i, tt := range tests {
+		k := kumi.New(&Router{})
+		k.Use(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				next.ServeHTTP(w, r)
+
+				// Middleware checks before writing an error response.
+				if rw := w.(kumi.ResponseWriter); !rw.HeaderWritten() {
+					t.Fatalf("(%d): expected header to be written", i)
+				}
+			})
+		})
+		k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			if w.(kumi.ResponseWriter).HeaderWritten() {
+				t.Fatalf("(%d): unexpected header written", i)
+			}
+			tt.handler(w, r)
+			if !w.(kumi.ResponseWriter).HeaderWritten() {
+				t.Fatalf("(%d): expected header to be written by the handler", i)
+			}
+		})
+
+		r, _ := http.NewRequest("GET", "/", nil)
+		w := &countingWriter{ResponseRecorder: httptest.NewRecorder()}
+		k.ServeHTTP(w, r)
+
+		if w.calls != 1 {
+			t.Fatalf("(%d): expected WriteHeader to be called once, given %d", i, w.calls)
+		} else if w.Code != tt.status {
+			t.Fatalf("(%d): unexpected status code: %d", i, w.Code)
+		}
+	}
+}
+
+// pushWriter records calls to Push.
+type pushWriter struct {
+	*httptest.ResponseRecorder
+	targets []string
+}
+
+func (w *pushWriter) Push(target string, opts *http.PushOptions) error {
+	w.targets = append(w.targets, target)
+	return nil
+}
+
+func TestWriter_Push(t *testing.T) {
+	var err error
+	k := kumi.New(&Router{})
+	k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		p, ok := w.(http.Pusher)
+		if !ok {
+			t.Fatalf("expected writer to implement http.Pusher: %T", w)
+		}
+		err = p.Push("/app.css", nil)
+	})
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	w := &pushWriter{ResponseRecorder: httptest.NewRecorder()}
+	k.ServeHTTP(w, r)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if len(w.targets) != 1 || w.targets[0] != "/app.css" {
+		t.Fatalf("unexpected push targets: %v", w.targets)
+	}
+
+	// Writers without push support return http.ErrNotSupported.
+	k.ServeHTTP(httptest.NewRecorder(), r)
+	if err != http.ErrNotSupported {
+		t.Fatalf("expected %v, given %v", http.ErrNotSupported, err)
+	}
+}
+
+// readFromWriter records calls to ReadFrom.
+type readFromWriter struct {
+	*httptest.ResponseRecorder
+	calls int
+}
+
+func (w *readFromWriter) ReadFrom(r io.Reader) (int64, error) {
+	w.calls++
+	return io.Copy(w.ResponseRecorder, r)
+}
+
+func TestWriter_ReadFrom(t *testing.T) {
+	body := strings.Repeat("a", 64*1024)
+	tests := []struct {
+		w     http.ResponseWriter
+		calls int
+	}{
+		{w: &readFromWriter{ResponseRecorder: httptest.NewRecorder()}, calls: 1},
+		{w: httptest.NewRecorder()}, // falls back to io.Copy
+	}
+
+	for i, tt := range tests {
+		var written int
+		k := kumi.New(&Router{})
+		k.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := w.(io.ReaderFrom); !ok {
+				t.Fatalf("(%d): expected writer to implement io.ReaderFrom: %T", i, w)
+			}
+			// Hide strings.Reader's WriteTo so io.Copy uses ReadFrom.
+			src := struct{ io.Reader }{strings.NewReader(body)}
+			if n, err := io.Copy(w, src); err != nil || n != int64(len(body)) {
+				t.Fatalf("(%d): unexpected copy result: %d, %v", i, n, err)
+			}
+			written = w.(kumi.ResponseWriter).Written()
+		})
+
+		r, _ := http.NewRequest("GET", "/", nil)
+		k.ServeHTTP(tt.w, r)
+
+		if written != len(body) {
+			t.Fatalf("(%d): expected Written() to be %d, given %d", i, len(body), written)
+		} else if rf, ok := tt.w.(*readFromWriter); ok && rf.calls != tt.calls {
+			t.Fatalf("(%d): expected ReadFrom to be called %d times, given %d", i, tt.calls, rf.calls)
+		}
+	}
+}